@@ -1,11 +1,19 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"net/http"
+	"net/netip"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 	"todo/pkg/api"
+	"todo/pkg/attachments"
+	"todo/pkg/backup"
 	"todo/pkg/db"
 
 	"github.com/joho/godotenv"
@@ -32,6 +40,60 @@ func main() {
 		dbFile = "data/scheduler.db"
 	}
 
+	// Get maximum page size for task listings from environment or use default
+	maxPageSize := envInt("TODO_MAX_PAGE_SIZE", 200)
+
+	// Get maximum number of operations in one batch request from environment or use default
+	maxBatchSize := envInt("TODO_MAX_BATCH_SIZE", 500)
+
+	// Get trash retention period in days from environment or use default
+	// Zero disables automatic purging
+	trashRetentionDays := envInt("TODO_TRASH_RETENTION_DAYS", 30)
+
+	// Get deadline for a single database call from environment or use default
+	// Zero disables the deadline
+	queryTimeout := envDuration("TODO_DB_TIMEOUT", 5*time.Second)
+
+	// Get SQLite connection settings from environment or use defaults
+	journalMode := os.Getenv("TODO_DB_JOURNAL_MODE")
+	if journalMode == "" {
+		journalMode = "WAL"
+	}
+	synchronous := os.Getenv("TODO_DB_SYNCHRONOUS")
+	if synchronous == "" {
+		synchronous = "NORMAL"
+	}
+	busyTimeout := envDuration("TODO_DB_BUSY_TIMEOUT", 5*time.Second)
+	foreignKeys := envBool("TODO_DB_FOREIGN_KEYS", true)
+	maxOpenConns := envInt("TODO_DB_MAX_OPEN_CONNS", 10)
+	maxIdleConns := envInt("TODO_DB_MAX_IDLE_CONNS", 5)
+
+	// Get directory for database snapshots from environment or use default
+	backupDir := os.Getenv("TODO_BACKUP_DIR")
+	if backupDir == "" {
+		backupDir = "backups"
+	}
+
+	// Get automatic backup period and retention from environment or use defaults
+	// Zero interval disables automatic backups, zero retention keeps all snapshots
+	backupInterval := envDuration("TODO_BACKUP_INTERVAL", 24*time.Hour)
+	backupKeep := envInt("TODO_BACKUP_KEEP", 7)
+	backupMaxAgeDays := envInt("TODO_BACKUP_MAX_AGE_DAYS", 30)
+
+	// Get attachments directory from environment or keep files next to the database
+	attachmentsDir := os.Getenv("TODO_ATTACHMENTS_DIR")
+	if attachmentsDir == "" {
+		attachmentsDir = filepath.Join(filepath.Dir(dbFile), "attachments")
+	}
+
+	// Get attachment size limits in megabytes from environment or use defaults
+	// Zero disables the limit
+	attachmentMaxMB := envInt("TODO_ATTACHMENT_MAX_MB", 10)
+	attachmentsQuotaMB := envInt("TODO_ATTACHMENTS_QUOTA_MB", 1024)
+
+	// Get reverse proxies allowed to pass the client address in X-Forwarded-For and X-Real-IP
+	trustedProxies := envPrefixes("TODO_TRUSTED_PROXIES")
+
 	// Debug: print all environment variables
 	log.Printf("DEBUG: TODO_PORT=%s", os.Getenv("TODO_PORT"))
 	log.Printf("DEBUG: TODO_DBFILE=%s", os.Getenv("TODO_DBFILE"))
@@ -43,14 +105,62 @@ func main() {
 	}
 
 	// Create storage
-	storage, err := db.NewStorage(dbFile)
+	storage, err := db.NewStorage(dbFile, db.Config{
+		QueryTimeout: queryTimeout,
+		JournalMode:  journalMode,
+		Synchronous:  synchronous,
+		BusyTimeout:  busyTimeout,
+		ForeignKeys:  foreignKeys,
+		MaxOpenConns: maxOpenConns,
+		MaxIdleConns: maxIdleConns,
+	})
 	if err != nil {
 		log.Fatal("Database initialization error:", err)
 	}
 	defer storage.Close()
 
+	backups := backup.NewManager(storage, backup.Config{
+		Dir:      backupDir,
+		Interval: backupInterval,
+		Keep:     backupKeep,
+		MaxAge:   time.Duration(backupMaxAgeDays) * 24 * time.Hour,
+	})
+
+	files := attachments.NewManager(storage, attachments.Config{
+		Dir:     attachmentsDir,
+		MaxSize: int64(attachmentMaxMB) << 20,
+		Quota:   int64(attachmentsQuotaMB) << 20,
+	})
+
+	// Run CLI subcommand instead of the server when one is given
+	if len(os.Args) > 1 {
+		if err := runCommand(backups, os.Args[1:]); err != nil {
+			log.Fatalf("Command %s failed: %v", os.Args[1], err)
+		}
+		return
+	}
+
+	if trashRetentionDays > 0 {
+		go purgeTrash(storage, files, time.Duration(trashRetentionDays)*24*time.Hour)
+	}
+
+	if backupInterval > 0 {
+		go backups.Schedule(context.Background())
+	}
+
 	// Create API
-	app := api.NewAPI(storage)
+	app := api.NewAPI(storage, backups, files, api.Config{
+		MaxPageSize:    maxPageSize,
+		MaxBatchSize:   maxBatchSize,
+		TrustedProxies: trustedProxies,
+	})
+
+	// Admin account signs in with TODO_PASSWORD, other accounts are registered by admins
+	if password := os.Getenv("TODO_PASSWORD"); password != "" {
+		if err := app.BootstrapAdmin(context.Background(), password); err != nil {
+			log.Fatal("Admin account initialization error:", err)
+		}
+	}
 
 	// Configure logger to show timestamp and file location
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
@@ -63,3 +173,118 @@ func main() {
 		log.Fatal("Server startup error:", err)
 	}
 }
+
+// runCommand executes CLI subcommand against the database
+//
+//	backup         - write verified snapshot into the backup directory
+//	restore <file> - replace the database with a snapshot file or a backup name
+func runCommand(backups *backup.Manager, args []string) error {
+	ctx := context.Background()
+
+	switch args[0] {
+	case "backup":
+		info, err := backups.Create(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Println(filepath.Join(backups.Dir(), info.Name))
+		return nil
+	case "restore":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: todo restore <file>")
+		}
+		if _, err := os.Stat(args[1]); err != nil {
+			return backups.Restore(ctx, args[1])
+		}
+		return backups.RestoreFile(ctx, args[1])
+	default:
+		return fmt.Errorf("unknown command %q, expected backup or restore", args[0])
+	}
+}
+
+// purgeTrash periodically removes tasks that stayed in the trash longer than retention
+// together with files attached to them
+func purgeTrash(storage *db.Storage, files *attachments.Manager, retention time.Duration) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		count, err := storage.PurgeTrash(context.Background(), time.Now().Add(-retention))
+		if err != nil {
+			log.Printf("ERROR: Automatic trash purge failed: %v", err)
+		}
+		if count > 0 {
+			if _, err := files.Cleanup(context.Background()); err != nil {
+				log.Printf("ERROR: Attachment cleanup failed: %v", err)
+			}
+		}
+		<-ticker.C
+	}
+}
+
+// envInt reads non-negative integer setting from environment or returns default
+func envInt(name string, def int) int {
+	s := os.Getenv(name)
+	if s == "" {
+		return def
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		log.Fatalf("Invalid %s: %s", name, s)
+	}
+	return n
+}
+
+// envBool reads boolean setting (true/false, 1/0) from environment or returns default
+func envBool(name string, def bool) bool {
+	s := os.Getenv(name)
+	if s == "" {
+		return def
+	}
+
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		log.Fatalf("Invalid %s: %s", name, s)
+	}
+	return b
+}
+
+// envPrefixes reads comma separated IP addresses and networks (e.g. "127.0.0.1,10.0.0.0/8") from environment
+func envPrefixes(name string) []netip.Prefix {
+	var prefixes []netip.Prefix
+	for _, s := range strings.Split(os.Getenv(name), ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		if !strings.Contains(s, "/") {
+			ip, err := netip.ParseAddr(s)
+			if err != nil {
+				log.Fatalf("Invalid %s: %s", name, s)
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(ip.Unmap(), ip.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			log.Fatalf("Invalid %s: %s", name, s)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes
+}
+
+// envDuration reads duration setting (e.g. "5s", "24h") from environment or returns default
+func envDuration(name string, def time.Duration) time.Duration {
+	s := os.Getenv(name)
+	if s == "" {
+		return def
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		log.Fatalf("Invalid %s: %s", name, s)
+	}
+	return d
+}
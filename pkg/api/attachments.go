@@ -0,0 +1,150 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"time"
+	"todo/pkg/models"
+)
+
+// attachmentsHandler lists files attached to the task
+// GET /api/task/attachments?id=task_id
+func (a *API) attachmentsHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	log.Printf("DEBUG: Retrieving attachments of task %s", id)
+
+	if id == "" {
+		log.Printf("WARN: Task ID not specified in attachments request")
+		sendError(w, "id not specified", http.StatusBadRequest)
+		return
+	}
+
+	attachments, err := a.storage.GetAttachments(r.Context(), id)
+	if err != nil {
+		sendStorageError(w, err)
+		return
+	}
+
+	log.Printf("INFO: Retrieved %d attachments of task %s", len(attachments), id)
+	sendJSON(w, map[string]any{"attachments": attachments})
+}
+
+// uploadAttachmentHandler attaches file from multipart form field "file" to the task
+// The body is streamed to disk, it is never held in memory as a whole
+// POST /api/task/attachments?id=task_id
+func (a *API) uploadAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	log.Printf("DEBUG: Received attachment upload for task %s", id)
+
+	if id == "" {
+		log.Printf("WARN: Task ID not specified in upload request")
+		sendError(w, "id not specified", http.StatusBadRequest)
+		return
+	}
+
+	mr, err := r.MultipartReader()
+	if err != nil {
+		log.Printf("WARN: Upload is not a multipart form: %v", err)
+		sendError(w, "multipart/form-data body expected", http.StatusBadRequest)
+		return
+	}
+
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Printf("WARN: Invalid multipart body: %v", err)
+			sendError(w, "invalid multipart body", http.StatusBadRequest)
+			return
+		}
+		if part.FormName() != "file" || part.FileName() == "" {
+			part.Close()
+			continue
+		}
+
+		attachment := &models.Attachment{
+			TaskID:      id,
+			Name:        filepath.Base(part.FileName()),
+			ContentType: part.Header.Get("Content-Type"),
+		}
+		err = a.attachments.Add(r.Context(), attachment, part)
+		part.Close()
+		if err != nil {
+			sendStorageError(w, err)
+			return
+		}
+
+		log.Printf("INFO: Attachment uploaded, ID: %s, task: %s, size: %d", attachment.ID, id, attachment.Size)
+		sendJSON(w, attachment)
+		return
+	}
+
+	log.Printf("WARN: Upload for task %s has no file field", id)
+	sendError(w, "file field is required", http.StatusBadRequest)
+}
+
+// downloadAttachmentHandler sends the attached file, supports range requests
+// GET /api/attachment?id=attachment_id
+func (a *API) downloadAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	log.Printf("DEBUG: Downloading attachment, ID: %s", id)
+
+	if id == "" {
+		log.Printf("WARN: Attachment ID not specified in download request")
+		sendError(w, "id not specified", http.StatusBadRequest)
+		return
+	}
+
+	attachment, f, err := a.attachments.Open(r.Context(), id)
+	if err != nil {
+		sendStorageError(w, err)
+		return
+	}
+	defer f.Close()
+
+	contentType := attachment.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Name}))
+	w.Header().Set("ETag", fmt.Sprintf(`"%s"`, attachment.SHA256))
+
+	modified, _ := time.Parse(time.RFC3339, attachment.CreatedAt)
+	http.ServeContent(w, r, attachment.Name, modified, f)
+}
+
+// deleteAttachmentHandler removes the attachment
+// DELETE /api/attachment?id=attachment_id
+func (a *API) deleteAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	log.Printf("DEBUG: Deleting attachment, ID: %s", id)
+
+	if id == "" {
+		log.Printf("WARN: Attachment ID not specified in delete request")
+		sendError(w, "id not specified", http.StatusBadRequest)
+		return
+	}
+
+	if err := a.attachments.Delete(r.Context(), id); err != nil {
+		sendStorageError(w, err)
+		return
+	}
+
+	log.Printf("INFO: Attachment deleted, ID: %s", id)
+	sendJSON(w, map[string]any{})
+}
+
+// cleanupAttachments removes files left by tasks purged from the trash
+// Failures are logged only, files are collected again on the next purge
+func (a *API) cleanupAttachments(r *http.Request) {
+	if _, err := a.attachments.Cleanup(r.Context()); err != nil {
+		log.Printf("ERROR: Attachment cleanup failed: %v", err)
+	}
+}
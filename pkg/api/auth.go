@@ -1,15 +1,24 @@
 package api
 
 import (
+	"context"
+	"crypto/pbkdf2"
+	"crypto/rand"
 	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
+	"todo/pkg/db"
 	"todo/pkg/models"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -18,15 +27,95 @@ import (
 // secretKey - JWT signing key
 var secretKey = []byte("secret-key")
 
+// PBKDF2 parameters of new password hashes, stored hashes keep their own iteration count
+const (
+	passwordIterations = 600000
+	passwordSaltSize   = 16
+	passwordKeySize    = 32
+)
+
+// errInvalidCredentials is returned for unknown login and wrong password alike
+var errInvalidCredentials = errors.New("invalid login or password")
+
 // generatePasswordHash creates SHA256 hash of password
+// Tokens carry it for the stored password hash, so they stop working once the password changes
 func generatePasswordHash(password string) string {
 	hash := sha256.Sum256([]byte(password))
 	return hex.EncodeToString(hash[:])
 }
 
+// hashPassword derives salted PBKDF2-SHA256 hash of the password
+// Format: pbkdf2-sha256$iterations$salt$key, salt and key in base64
+func hashPassword(password string) (string, error) {
+	salt := make([]byte, passwordSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key, err := pbkdf2.Key(sha256.New, password, salt, passwordIterations, passwordKeySize)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("pbkdf2-sha256$%d$%s$%s", passwordIterations,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// checkPassword reports whether password matches hash made by hashPassword
+// Empty hash matches no password
+func checkPassword(password, hash string) bool {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 4 || parts[0] != "pbkdf2-sha256" {
+		return false
+	}
+
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations < 1 {
+		return false
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	want, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return false
+	}
+
+	key, err := pbkdf2.Key(sha256.New, password, salt, iterations, len(want))
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare(key, want) == 1
+}
+
+// BootstrapAdmin sets password of the initial admin account
+// The password is stored only when it differs from the current one, so tokens survive restarts
+func (a *API) BootstrapAdmin(ctx context.Context, password string) error {
+	admin, err := a.storage.GetUser(ctx, db.AdminID)
+	if err != nil {
+		return err
+	}
+	if checkPassword(password, admin.PasswordHash) {
+		return nil
+	}
+
+	hash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+	if err := a.storage.SetPassword(ctx, admin.ID, hash); err != nil {
+		return err
+	}
+
+	log.Printf("INFO: Password of admin account %s set from TODO_PASSWORD", admin.Login)
+	return nil
+}
+
 // SignInHandler handles user authentication
-// POST /api/signin
-func SignInHandler(w http.ResponseWriter, r *http.Request) {
+// Without login the admin account signs in, as before user accounts existed
+// POST /api/signin {"login": "name", "password": "secret"}
+func (a *API) SignInHandler(w http.ResponseWriter, r *http.Request) {
 	log.Printf("DEBUG: Received signin request from IP %s", r.RemoteAddr)
 
 	if r.Method != http.MethodPost {
@@ -42,28 +131,39 @@ func SignInHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get password from environment variable
-	envPassword := os.Getenv("TODO_PASSWORD")
-	if envPassword == "" {
-		log.Printf("ERROR: TODO_PASSWORD environment variable not set")
-		sendError(w, "authentication not configured", http.StatusInternalServerError)
+	var user *models.User
+	var err error
+	if input.Login == "" {
+		user, err = a.storage.GetUser(r.Context(), db.AdminID)
+	} else {
+		user, err = a.storage.GetUserByLogin(r.Context(), input.Login)
+	}
+	if errors.Is(err, db.ErrNotFound) {
+		err = errInvalidCredentials
+	}
+	if err == nil && !checkPassword(input.Password, user.PasswordHash) {
+		err = errInvalidCredentials
+	}
+	if errors.Is(err, errInvalidCredentials) {
+		log.Printf("WARN: Failed login attempt for '%s' from IP %s", input.Login, r.RemoteAddr)
+		sendError(w, err.Error(), http.StatusUnauthorized)
 		return
 	}
-
-	// Validate password
-	if input.Password != envPassword {
-		log.Printf("WARN: Failed login attempt from IP %s", r.RemoteAddr)
-		sendError(w, "invalid password", http.StatusUnauthorized)
+	if err != nil {
+		sendStorageError(w, err)
 		return
 	}
 
-	log.Printf("INFO: User authenticated successfully from IP %s", r.RemoteAddr)
+	log.Printf("INFO: User %s authenticated successfully from IP %s", user.Login, r.RemoteAddr)
 
 	// Create JWT token
 	expirationTime := time.Now().Add(8 * time.Hour)
 	claims := &models.Claims{
-		PasswordHash: generatePasswordHash(envPassword),
+		UserID:       user.ID,
+		Role:         user.Role,
+		PasswordHash: generatePasswordHash(user.PasswordHash),
 		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   user.Login,
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
@@ -88,24 +188,17 @@ func SignInHandler(w http.ResponseWriter, r *http.Request) {
 		Path:     "/",
 	})
 
-	//TODO Print token for testing purposes (remove in production)
-	fmt.Println("***\nToken for testing:\n" + tokenString + "\n***")
-
 	sendJSON(w, models.SignInResponse{Token: tokenString})
 }
 
 // AuthMiddleware validates JWT tokens for protected routes
-func AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
+// and puts the signed in user into the request context.
+// A presented token is always checked. Without TODO_PASSWORD requests without a token act as the admin
+// as long as no other accounts exist
+func (a *API) AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		log.Printf("DEBUG: AuthMiddleware checking request: %s %s", r.Method, r.URL.Path)
 
-		envPassword := os.Getenv("TODO_PASSWORD")
-		if envPassword == "" {
-			log.Printf("DEBUG: Authentication disabled, TODO_PASSWORD not set")
-			next(w, r)
-			return
-		}
-
 		// Extract token from cookie or Authorization header
 		var tokenString string
 		cookie, err := r.Cookie("token")
@@ -123,40 +216,113 @@ func AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
 			}
 		}
 
-		if tokenString == "" {
-			log.Printf("WARN: No authentication token provided for %s %s from IP %s", r.Method, r.URL.Path, r.RemoteAddr)
-			sendError(w, "Authentification required", http.StatusUnauthorized)
-			return
+		var user *models.User
+		if tokenString != "" {
+			user, err = a.tokenUser(r.Context(), tokenString)
+			if err != nil {
+				log.Printf("WARN: Token rejected for %s %s from IP %s: %v", r.Method, r.URL.Path, r.RemoteAddr, err)
+				sendError(w, "invalid token", http.StatusUnauthorized)
+				return
+			}
+		} else {
+			anonymous, err := a.anonymousAllowed(r.Context())
+			if err != nil {
+				sendStorageError(w, err)
+				return
+			}
+			if !anonymous {
+				log.Printf("WARN: No authentication token provided for %s %s from IP %s", r.Method, r.URL.Path, r.RemoteAddr)
+				sendError(w, "Authentification required", http.StatusUnauthorized)
+				return
+			}
+
+			log.Printf("DEBUG: Authentication disabled, TODO_PASSWORD not set")
+			user, err = a.storage.GetUser(r.Context(), db.AdminID)
+			if err != nil {
+				sendStorageError(w, err)
+				return
+			}
 		}
 
-		// Parse and validate JWT token
-		claims := &models.Claims{}
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (any, error) {
-			return secretKey, nil
-		})
+		log.Printf("DEBUG: User %s authenticated successfully for %s %s", user.Login, r.Method, r.URL.Path)
 
-		if err != nil {
-			log.Printf("WARN: JWT token parsing failed: %v", err)
-			sendError(w, "invalid token", http.StatusUnauthorized)
-			return
-		}
+		next(w, r.WithContext(db.WithUser(r.Context(), user)))
+	}
+}
 
-		if !token.Valid {
-			log.Printf("WARN: Invalid JWT token provided")
-			sendError(w, "invalid token", http.StatusUnauthorized)
-			return
-		}
+// anonymousAllowed reports whether requests without a token act as the admin
+// It is so only without TODO_PASSWORD and while the admin is the only account,
+// otherwise anyone could gain rights of the admin by dropping the token
+func (a *API) anonymousAllowed(ctx context.Context) (bool, error) {
+	if os.Getenv("TODO_PASSWORD") != "" {
+		return false, nil
+	}
+	users, err := a.storage.HasUsers(ctx)
+	return !users, err
+}
 
-		// Check if password has changed (invalidate old tokens)
-		currentPasswordHash := generatePasswordHash(envPassword)
-		if claims.PasswordHash != currentPasswordHash {
-			log.Printf("WARN: JWT token rejected - password changed, IP: %s", r.RemoteAddr)
-			sendError(w, "token expired", http.StatusUnauthorized)
+// tokenUser parses JWT token and returns its user
+// Tokens of deleted users and tokens issued before a password or role change are rejected
+func (a *API) tokenUser(ctx context.Context, tokenString string) (*models.User, error) {
+	claims := &models.Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (any, error) {
+		return secretKey, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid || claims.UserID == "" {
+		return nil, errors.New("invalid token")
+	}
+
+	user, err := a.storage.GetUser(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check if password has changed (invalidate old tokens)
+	if claims.PasswordHash != generatePasswordHash(user.PasswordHash) {
+		return nil, errors.New("password changed")
+	}
+	// Check if role has changed, the token must not keep rights taken away
+	if claims.Role != user.Role {
+		return nil, errors.New("role changed")
+	}
+	return user, nil
+}
+
+// requireRole lets only accounts with one of the roles through
+func requireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user := db.UserFrom(r.Context())
+			if user == nil || !slices.Contains(roles, user.Role) {
+				log.Printf("WARN: Request to %s %s rejected, role %s is not one of %v", r.Method, r.URL.Path, userRole(user), roles)
+				sendError(w, "insufficient rights", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// restrictReadOnly lets read-only accounts call only GET endpoints
+func restrictReadOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := db.UserFrom(r.Context())
+		if r.Method != http.MethodGet && r.Method != http.MethodHead && (user == nil || user.Role == models.RoleReadOnly) {
+			log.Printf("WARN: Request to %s %s rejected, role %s is read-only", r.Method, r.URL.Path, userRole(user))
+			sendError(w, "read-only access", http.StatusForbidden)
 			return
 		}
+		next.ServeHTTP(w, r)
+	})
+}
 
-		log.Printf("DEBUG: User authenticated successfully for %s %s", r.Method, r.URL.Path)
-
-		next(w, r)
+// userRole returns role of the user for logging
+func userRole(user *models.User) string {
+	if user == nil {
+		return "none"
 	}
+	return user.Role
 }
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"log"
+	"net/http"
+)
+
+// backupHandler takes an online snapshot of the database
+// POST /api/admin/backup
+func (a *API) backupHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("DEBUG: Received backup request from %s", a.clientID(r))
+
+	info, err := a.backups.Create(r.Context())
+	if err != nil {
+		sendStorageError(w, err)
+		return
+	}
+
+	sendJSON(w, info)
+}
+
+// backupsHandler lists snapshots in the backup directory, newest first
+// GET /api/admin/backups
+func (a *API) backupsHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("DEBUG: Listing backups")
+
+	backups, err := a.backups.List()
+	if err != nil {
+		sendStorageError(w, err)
+		return
+	}
+
+	sendJSON(w, map[string]any{"backups": backups})
+}
+
+// restoreBackupHandler replaces the database with a snapshot from the backup directory
+// POST /api/admin/restore?name=scheduler_YYYYMMDD_HHMMSS.db
+func (a *API) restoreBackupHandler(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	log.Printf("DEBUG: Received restore request from %s, backup: %s", a.clientID(r), name)
+
+	if name == "" {
+		log.Printf("WARN: Backup name not specified in restore request")
+		sendError(w, "name not specified", http.StatusBadRequest)
+		return
+	}
+
+	if err := a.backups.Restore(r.Context(), name); err != nil {
+		sendStorageError(w, err)
+		return
+	}
+
+	log.Printf("INFO: Database restored from backup %s", name)
+	sendJSON(w, map[string]any{})
+}
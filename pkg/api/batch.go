@@ -0,0 +1,160 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"todo/pkg/db"
+	"todo/pkg/models"
+)
+
+// batchHandler runs a list of task operations in one transaction
+// In atomic mode (default) the first failed operation rolls back the whole batch
+// and its status is returned. In per_item mode each operation runs in a savepoint,
+// failed ones are skipped and every operation gets its own status in results
+// POST /api/tasks/batch
+func (a *API) batchHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("DEBUG: Received batch request")
+
+	var input models.BatchInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		log.Printf("WARN: Invalid JSON in batch request: %v", err)
+		sendError(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if input.Mode == "" {
+		input.Mode = models.BatchAtomic
+	}
+	if input.Mode != models.BatchAtomic && input.Mode != models.BatchPerItem {
+		log.Printf("WARN: Unknown batch mode: %s", input.Mode)
+		sendError(w, "mode must be atomic or per_item", http.StatusBadRequest)
+		return
+	}
+	if len(input.Operations) == 0 {
+		sendError(w, "operations are empty", http.StatusBadRequest)
+		return
+	}
+	if len(input.Operations) > a.config.MaxBatchSize {
+		log.Printf("WARN: Batch of %d operations exceeds limit %d", len(input.Operations), a.config.MaxBatchSize)
+		sendError(w, fmt.Sprintf("too many operations, limit is %d", a.config.MaxBatchSize), http.StatusBadRequest)
+		return
+	}
+
+	actor := a.clientID(r)
+	atomic := input.Mode == models.BatchAtomic
+	results := make([]models.BatchResult, len(input.Operations))
+	failed := -1
+
+	err := a.storage.Batch(r.Context(), func(ctx context.Context, tx *db.Tx) error {
+		for i, op := range input.Operations {
+			res := &results[i]
+			res.Index, res.Op, res.Status = i, op.Op, http.StatusOK
+
+			apply := func() error {
+				id, err := a.applyOperation(ctx, tx, op, actor)
+				res.ID = id
+				return err
+			}
+
+			var err error
+			if atomic {
+				err = apply()
+			} else {
+				err = tx.Savepoint(ctx, apply)
+			}
+			if err == nil {
+				continue
+			}
+
+			res.Status, res.Error = publicError(err)
+			if atomic {
+				failed = i
+				return fmt.Errorf("operation %d (%s): %w", i, op.Op, err)
+			}
+			log.Printf("WARN: Batch operation %d (%s) skipped: %v", i, op.Op, err)
+		}
+		return nil
+	})
+
+	if err != nil && failed >= 0 {
+		res := results[failed]
+		log.Printf("WARN: Batch rolled back at operation %d: %v", failed, err)
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.WriteHeader(res.Status)
+		json.NewEncoder(w).Encode(map[string]any{
+			"error": fmt.Sprintf("operation %d (%s): %s", failed, res.Op, res.Error),
+			"index": failed,
+		})
+		return
+	}
+	if err != nil {
+		sendStorageError(w, err)
+		return
+	}
+
+	log.Printf("INFO: Batch of %d operations applied, mode: %s", len(input.Operations), input.Mode)
+	sendJSON(w, map[string]any{"results": results})
+}
+
+// applyOperation runs single batch operation within the transaction
+// Returns ID of the affected task
+func (a *API) applyOperation(ctx context.Context, tx *db.Tx, op models.BatchOperation, actor string) (string, error) {
+	switch op.Op {
+	case models.BatchCreate:
+		if op.Task == nil {
+			return "", &db.ValidationError{Field: "task", Message: "required"}
+		}
+		input := *op.Task
+		input.ID, input.Version = "", ""
+		task, err := taskFromInput(input)
+		if err != nil {
+			return "", err
+		}
+		if _, err := tx.AddTask(ctx, task, actor); err != nil {
+			return "", err
+		}
+		return task.ID, nil
+
+	case models.BatchUpdate:
+		if op.Task == nil || op.Task.ID == "" {
+			return "", &db.ValidationError{Field: "task.id", Message: "required"}
+		}
+		input := *op.Task
+		if updatesUndated(input) {
+			current, err := tx.GetTask(ctx, input.ID)
+			if err != nil {
+				return input.ID, err
+			}
+			keepUndated(&input, current)
+		}
+		task, err := taskFromInput(input)
+		if err != nil {
+			return op.Task.ID, err
+		}
+		if task.Version == "" {
+			return task.ID, errVersionRequired
+		}
+		_, err = tx.UpdateTask(ctx, task, models.ActionUpdate, actor)
+		return task.ID, err
+
+	case models.BatchDone:
+		if op.ID == "" {
+			return "", &db.ValidationError{Field: "id", Message: "required"}
+		}
+		_, _, err := tx.CompleteTask(ctx, op.ID, op.Note, actor, NextDate)
+		return op.ID, err
+
+	case models.BatchDelete:
+		if op.ID == "" {
+			return "", &db.ValidationError{Field: "id", Message: "required"}
+		}
+		_, err := tx.DeleteTask(ctx, op.ID, actor)
+		return op.ID, err
+
+	default:
+		return "", &db.ValidationError{Field: "op", Message: fmt.Sprintf("unknown operation %q", op.Op)}
+	}
+}
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"todo/pkg/models"
+)
+
+// checklistHandler lists checklist items of the task in order
+// GET /api/checklist?task=task_id
+func (a *API) checklistHandler(w http.ResponseWriter, r *http.Request) {
+	taskID := r.URL.Query().Get("task")
+	log.Printf("DEBUG: Retrieving checklist of task %s", taskID)
+
+	if taskID == "" {
+		log.Printf("WARN: Task ID not specified in checklist request")
+		sendError(w, "task not specified", http.StatusBadRequest)
+		return
+	}
+
+	items, err := a.storage.GetChecklist(r.Context(), taskID)
+	if err != nil {
+		sendStorageError(w, err)
+		return
+	}
+
+	log.Printf("INFO: Retrieved %d checklist items of task %s", len(items), taskID)
+	sendJSON(w, map[string]any{"items": items})
+}
+
+// addChecklistItemHandler appends item to the checklist of the task
+// POST /api/checklist?task=task_id {"title": "..."}
+func (a *API) addChecklistItemHandler(w http.ResponseWriter, r *http.Request) {
+	taskID := r.URL.Query().Get("task")
+	log.Printf("DEBUG: Received checklist item creation request, task: %s", taskID)
+
+	if taskID == "" {
+		log.Printf("WARN: Task ID not specified in checklist item creation request")
+		sendError(w, "task not specified", http.StatusBadRequest)
+		return
+	}
+
+	var input models.ChecklistItem
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		log.Printf("WARN: Invalid JSON in checklist item creation request: %v", err)
+		sendError(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	input.TaskID = taskID
+
+	id, err := a.storage.AddChecklistItem(r.Context(), &input)
+	if err != nil {
+		sendStorageError(w, err)
+		return
+	}
+
+	log.Printf("INFO: Checklist item created, ID: %d, task: %s", id, taskID)
+	sendJSON(w, map[string]any{"id": fmt.Sprintf("%d", id)})
+}
+
+// updateChecklistItemHandler changes title and done state of the item
+// PUT /api/checklist {"id": "1", "title": "...", "done": true}
+func (a *API) updateChecklistItemHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("DEBUG: Received checklist item update request")
+
+	var input models.ChecklistItem
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		log.Printf("WARN: Invalid JSON in checklist item update request: %v", err)
+		sendError(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if input.ID == "" {
+		log.Printf("WARN: Missing item ID in checklist update request")
+		sendError(w, "id is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := a.storage.UpdateChecklistItem(r.Context(), &input); err != nil {
+		sendStorageError(w, err)
+		return
+	}
+
+	log.Printf("INFO: Checklist item updated, ID: %s", input.ID)
+	sendJSON(w, map[string]any{})
+}
+
+// toggleChecklistItemHandler flips done state of the item
+// POST /api/checklist/toggle?id=item_id
+func (a *API) toggleChecklistItemHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	log.Printf("DEBUG: Toggling checklist item, ID: %s", id)
+
+	if id == "" {
+		log.Printf("WARN: Item ID not specified in toggle request")
+		sendError(w, "id not specified", http.StatusBadRequest)
+		return
+	}
+
+	item, err := a.storage.ToggleChecklistItem(r.Context(), id)
+	if err != nil {
+		sendStorageError(w, err)
+		return
+	}
+
+	log.Printf("INFO: Checklist item toggled, ID: %s, done: %t", id, item.Done)
+	sendJSON(w, item)
+}
+
+// reorderChecklistHandler sets new order of checklist items
+// POST /api/checklist/reorder?task=task_id {"items": ["3", "1", "2"]}
+func (a *API) reorderChecklistHandler(w http.ResponseWriter, r *http.Request) {
+	taskID := r.URL.Query().Get("task")
+	log.Printf("DEBUG: Received checklist reorder request, task: %s", taskID)
+
+	if taskID == "" {
+		log.Printf("WARN: Task ID not specified in checklist reorder request")
+		sendError(w, "task not specified", http.StatusBadRequest)
+		return
+	}
+
+	var input models.ChecklistReorderInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		log.Printf("WARN: Invalid JSON in checklist reorder request: %v", err)
+		sendError(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if err := a.storage.ReorderChecklist(r.Context(), taskID, input.Items); err != nil {
+		sendStorageError(w, err)
+		return
+	}
+
+	log.Printf("INFO: Checklist of task %s reordered", taskID)
+	sendJSON(w, map[string]any{})
+}
+
+// deleteChecklistItemHandler removes the item from its checklist
+// DELETE /api/checklist?id=item_id
+func (a *API) deleteChecklistItemHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	log.Printf("DEBUG: Deleting checklist item, ID: %s", id)
+
+	if id == "" {
+		log.Printf("WARN: Item ID not specified in delete request")
+		sendError(w, "id not specified", http.StatusBadRequest)
+		return
+	}
+
+	if err := a.storage.DeleteChecklistItem(r.Context(), id); err != nil {
+		sendStorageError(w, err)
+		return
+	}
+
+	log.Printf("INFO: Checklist item deleted, ID: %s", id)
+	sendJSON(w, map[string]any{})
+}
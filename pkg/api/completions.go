@@ -0,0 +1,48 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"time"
+	"todo/pkg/db"
+)
+
+// completionsHandler retrieves completion log of a task or a date range
+// GET /api/completions?id=task_id&from=YYYYMMDD&to=YYYYMMDD
+func (a *API) completionsHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := db.CompletionFilter{
+		TaskID: query.Get("id"),
+		From:   query.Get("from"),
+		To:     query.Get("to"),
+	}
+	log.Printf("DEBUG: Retrieving completions, task: '%s', from: '%s', to: '%s'", filter.TaskID, filter.From, filter.To)
+
+	for _, date := range []string{filter.From, filter.To} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse(dateLayout, date); err != nil {
+			log.Printf("WARN: Invalid date in completions request: %s", date)
+			sendError(w, "invalid date format", http.StatusBadRequest)
+			return
+		}
+	}
+
+	n, err := a.pageSize(r)
+	if err != nil {
+		log.Printf("WARN: Invalid page size: %v", err)
+		sendError(w, "invalid limit", http.StatusBadRequest)
+		return
+	}
+	filter.Limit = n
+
+	completions, err := a.storage.GetCompletions(r.Context(), filter)
+	if err != nil {
+		sendStorageError(w, err)
+		return
+	}
+
+	log.Printf("INFO: Retrieved %d completions", len(completions.Completions))
+	sendJSON(w, completions)
+}
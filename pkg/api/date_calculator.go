@@ -2,19 +2,31 @@ package api
 
 import (
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
 )
 
+// dateSomeday - date of a task to be scheduled some day, such tasks wait in the inbox without date
+const dateSomeday = "someday"
+
 // NormalizeDate validates and normalizes task date
 // For past dates without repetition, sets to today
 // For past dates with repetition, calculates next occurrence
-// Returns normalized date in YYYYMMDD format
+// "someday" leaves one-time task without date
+// Returns normalized date in YYYYMMDD format, empty for undated tasks
 func NormalizeDate(dateStart, repeat string) (string, error) {
 	now := time.Now()
 	today := now.Format(dateLayout)
 
+	if dateStart == dateSomeday {
+		if repeat != "" {
+			return "", fmt.Errorf("recurring task needs a date")
+		}
+		return "", nil
+	}
+
 	if dateStart == "" || dateStart == "today" {
 		dateStart = today
 	}
@@ -46,6 +58,76 @@ func NormalizeDate(dateStart, repeat string) (string, error) {
 	return dateStart, nil
 }
 
+// snoozeOffset matches relative snooze offsets in days, weeks or months: +3d, +2w, +1m
+var snoozeOffset = regexp.MustCompile(`^\+(\d{1,3})([dwm])$`)
+
+// SnoozeDate calculates the date a task due on date is snoozed to
+// Offsets "+3d", "+2w", "+1m" and "next monday" count from the due date,
+// from today for undated and overdue tasks; a date in YYYYMMDD format must not be in the past
+// Returns new date in YYYYMMDD format
+func SnoozeDate(now time.Time, date, to string) (string, error) {
+	today := now.Format(dateLayout)
+	to = strings.ToLower(strings.TrimSpace(to))
+	if to == "" {
+		return "", fmt.Errorf("snooze date not specified")
+	}
+
+	if _, err := time.Parse(dateLayout, to); err == nil {
+		if to < today {
+			return "", fmt.Errorf("date is in the past")
+		}
+		return to, nil
+	}
+
+	if date < today {
+		date = today
+	}
+	base, err := time.Parse(dateLayout, date)
+	if err != nil {
+		return "", fmt.Errorf("invalid date format")
+	}
+
+	if m := snoozeOffset.FindStringSubmatch(to); m != nil {
+		num, _ := strconv.Atoi(m[1])
+		if num < 1 {
+			return "", fmt.Errorf("snooze offset must be positive")
+		}
+		switch m[2] {
+		case "d":
+			base = base.AddDate(0, 0, num)
+		case "w":
+			base = base.AddDate(0, 0, 7*num)
+		case "m":
+			base = addMonths(base, num)
+		}
+		return base.Format(dateLayout), nil
+	}
+
+	if name, ok := strings.CutPrefix(to, "next "); ok {
+		for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+			if strings.ToLower(weekday.String()) != strings.TrimSpace(name) {
+				continue
+			}
+			base = base.AddDate(0, 0, 1)
+			for base.Weekday() != weekday {
+				base = base.AddDate(0, 0, 1)
+			}
+			return base.Format(dateLayout), nil
+		}
+		return "", fmt.Errorf("unknown weekday: %s", name)
+	}
+
+	return "", fmt.Errorf("unknown snooze offset: %s", to)
+}
+
+// addMonths moves date by num months, a day missing in the target month becomes its last day
+// e.g. January 31 plus one month is February 28 (29 in leap years)
+func addMonths(date time.Time, num int) time.Time {
+	first := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location()).AddDate(0, num, 0)
+	last := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(date.Day(), last)-1)
+}
+
 // NextDate calculates next occurrence date for recurring tasks
 // Supports daily (d), weekly (w), monthly (m), and yearly (y) rules
 // Returns next date in YYYYMMDD format
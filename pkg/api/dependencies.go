@@ -0,0 +1,27 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"todo/pkg/db"
+)
+
+// planHandler retrieves active tasks in dependency order, every task follows its blockers
+// With id the plan holds the task and all tasks blocking it, directly or through other tasks
+// GET /api/tasks/plan?id=task_id&project=id
+func (a *API) planHandler(w http.ResponseWriter, r *http.Request) {
+	filter := db.PlanFilter{
+		Task:    r.URL.Query().Get("id"),
+		Project: r.URL.Query().Get("project"),
+	}
+	log.Printf("DEBUG: Retrieving plan, task: '%s', project: '%s'", filter.Task, filter.Project)
+
+	plan, err := a.storage.GetPlan(r.Context(), filter)
+	if err != nil {
+		sendStorageError(w, err)
+		return
+	}
+
+	log.Printf("INFO: Retrieved plan of %d tasks", len(plan.Tasks))
+	sendJSON(w, plan)
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"todo/pkg/attachments"
+	"todo/pkg/db"
+)
+
+// errorStatus maps storage, attachment and context errors to HTTP status codes
+func errorStatus(err error) int {
+	var verr *db.ValidationError
+	switch {
+	case errors.Is(err, db.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, db.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, db.ErrForbidden):
+		return http.StatusForbidden
+	case errors.As(err, &verr):
+		return http.StatusBadRequest
+	case errors.Is(err, attachments.ErrTooLarge), errors.Is(err, attachments.ErrQuotaExceeded):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// publicError returns status of err and message safe to show to the client
+// Server error details are hidden
+func publicError(err error) (int, string) {
+	status := errorStatus(err)
+
+	switch {
+	case status == http.StatusServiceUnavailable:
+		return status, "database timeout"
+	case status >= http.StatusInternalServerError:
+		return status, "internal server error"
+	default:
+		return status, err.Error()
+	}
+}
+
+// sendStorageError sends error returned by the storage layer with mapped status
+// Client errors keep their message, server errors are logged and hidden
+func sendStorageError(w http.ResponseWriter, err error) {
+	status, message := publicError(err)
+
+	switch {
+	case status == http.StatusServiceUnavailable:
+		log.Printf("ERROR: Database call timed out: %v", err)
+	case status >= http.StatusInternalServerError:
+		log.Printf("ERROR: Database error: %v", err)
+	default:
+		log.Printf("WARN: Request rejected by storage: %v", err)
+	}
+	sendError(w, message, status)
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+)
+
+// healthHandler reports database availability and outcome of the last backup
+// Responds 503 when the database is unreachable, a failed backup only marks status as degraded
+// GET /api/health
+func (a *API) healthHandler(w http.ResponseWriter, r *http.Request) {
+	resp := map[string]any{
+		"status":   "ok",
+		"database": "ok",
+	}
+	status := http.StatusOK
+
+	if err := a.storage.Ping(r.Context()); err != nil {
+		log.Printf("ERROR: Health check failed, database unreachable: %v", err)
+		resp["status"] = "unavailable"
+		resp["database"] = "unreachable"
+		status = http.StatusServiceUnavailable
+	}
+
+	backup := a.backups.Status()
+	if backup.LastStatus == "failed" && status == http.StatusOK {
+		resp["status"] = "degraded"
+	}
+	resp["backup"] = backup
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
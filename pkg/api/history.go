@@ -0,0 +1,182 @@
+package api
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"todo/pkg/db"
+	"todo/pkg/models"
+)
+
+// clientID identifies the client performing the request for the audit trail
+// as login@address of the signed in user, or the address alone
+func (a *API) clientID(r *http.Request) string {
+	addr := clientAddr(r, a.config.TrustedProxies)
+	if user := db.UserFrom(r.Context()); user != nil {
+		return user.Login + "@" + addr
+	}
+	return addr
+}
+
+// clientAddr returns IP address of the client
+// Proxy headers are taken into account only when the request comes from a trusted proxy:
+// X-Forwarded-For is read from the right, skipping trusted proxies, then X-Real-IP is used
+func clientAddr(r *http.Request, trusted []netip.Prefix) string {
+	peer := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		peer = host
+	}
+	if !isTrusted(peer, trusted) {
+		return peer
+	}
+
+	if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+		hops := strings.Split(fwd, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if i == 0 || !isTrusted(hop, trusted) {
+				return hop
+			}
+		}
+	}
+	if ip := strings.TrimSpace(r.Header.Get("X-Real-IP")); ip != "" {
+		return ip
+	}
+	return peer
+}
+
+// isTrusted reports whether addr belongs to one of the trusted proxy networks
+func isTrusted(addr string, trusted []netip.Prefix) bool {
+	ip, err := netip.ParseAddr(addr)
+	if err != nil {
+		return false
+	}
+	ip = ip.Unmap()
+	for _, prefix := range trusted {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// historyHandler retrieves revision history of a task
+// GET /api/task/history?id=task_id
+func (a *API) historyHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	log.Printf("DEBUG: Retrieving history of task %s", id)
+
+	if id == "" {
+		log.Printf("WARN: Task ID not specified in history request")
+		sendError(w, "id not specified", http.StatusBadRequest)
+		return
+	}
+
+	n, err := a.pageSize(r)
+	if err != nil {
+		log.Printf("WARN: Invalid page size: %v", err)
+		sendError(w, "invalid limit", http.StatusBadRequest)
+		return
+	}
+
+	revisions, err := a.storage.GetRevisions(r.Context(), id, n)
+	if err != nil {
+		sendStorageError(w, err)
+		return
+	}
+
+	log.Printf("INFO: Retrieved %d revisions of task %s", len(revisions.Revisions), id)
+	sendJSON(w, revisions)
+}
+
+// revertTaskHandler restores task fields from an earlier revision
+// The revert itself is recorded as a new revision
+// POST /api/task/revert?id=task_id&revision=revision_id
+func (a *API) revertTaskHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	revID := r.URL.Query().Get("revision")
+	log.Printf("DEBUG: Reverting task %s to revision %s", id, revID)
+
+	if id == "" || revID == "" {
+		log.Printf("WARN: Task or revision ID not specified in revert request")
+		sendError(w, "id and revision are required", http.StatusBadRequest)
+		return
+	}
+
+	rev, err := a.storage.GetRevision(r.Context(), id, revID)
+	if err != nil {
+		sendStorageError(w, err)
+		return
+	}
+
+	if rev.Snapshot == nil || rev.Snapshot.DeletedAt != "" {
+		log.Printf("WARN: Revision %s of task %s has no state to revert to", revID, id)
+		sendError(w, "revision has no state to revert to", http.StatusBadRequest)
+		return
+	}
+
+	// Snapshot without date is an undated task
+	date := rev.Snapshot.Date
+	if date == "" {
+		date = dateSomeday
+	}
+	date, err = NormalizeDate(date, rev.Snapshot.Repeat)
+	if err != nil {
+		log.Printf("WARN: Date normalization failed for revision %s: %v", revID, err)
+		sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	start, err := normalizeStart(rev.Snapshot.Start, rev.Snapshot.Date, date)
+	if err != nil {
+		sendStorageError(w, err)
+		return
+	}
+
+	// Snapshot without project means the task had none
+	project := ""
+	if rev.Snapshot.Project != nil {
+		project = *rev.Snapshot.Project
+	}
+
+	// Snapshot without tags means the task had none
+	tags := rev.Snapshot.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	// Snapshot without blockers means the task had none
+	blockedBy := rev.Snapshot.BlockedBy
+	if blockedBy == nil {
+		blockedBy = []string{}
+	}
+
+	// Snapshot without assignee means the task had none
+	assignee := ""
+	if rev.Snapshot.Assignee != nil {
+		assignee = *rev.Snapshot.Assignee
+	}
+
+	task := models.Task{
+		ID:        id,
+		Date:      date,
+		Start:     start,
+		Title:     rev.Snapshot.Title,
+		Comment:   rev.Snapshot.Comment,
+		Repeat:    rev.Snapshot.Repeat,
+		Tags:      tags,
+		Project:   &project,
+		Priority:  rev.Snapshot.Priority,
+		BlockedBy: blockedBy,
+		Assignee:  &assignee,
+	}
+
+	if _, err := a.storage.UpdateTask(r.Context(), &task, models.ActionRevert, a.clientID(r)); err != nil {
+		sendStorageError(w, err)
+		return
+	}
+
+	log.Printf("INFO: Task %s reverted to revision %s", id, revID)
+	sendJSON(w, map[string]any{})
+}
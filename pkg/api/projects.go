@@ -0,0 +1,143 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"todo/pkg/models"
+)
+
+// projectsHandler lists projects with numbers of active tasks
+// Archived projects are listed only with archived=1
+// GET /api/projects?archived=1
+func (a *API) projectsHandler(w http.ResponseWriter, r *http.Request) {
+	archived := r.URL.Query().Get("archived") == "1"
+	log.Printf("DEBUG: Retrieving projects, archived: %t", archived)
+
+	projects, err := a.storage.GetProjects(r.Context(), archived)
+	if err != nil {
+		sendStorageError(w, err)
+		return
+	}
+
+	log.Printf("INFO: Retrieved %d projects", len(projects))
+	sendJSON(w, map[string]any{"projects": projects})
+}
+
+// addProjectHandler creates a new project
+// POST /api/project {"name": "Работа", "color": "#3366ff"}
+func (a *API) addProjectHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("DEBUG: Received project creation request")
+
+	var input models.Project
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		log.Printf("WARN: Invalid JSON in project creation request: %v", err)
+		sendError(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	id, err := a.storage.AddProject(r.Context(), &input)
+	if err != nil {
+		sendStorageError(w, err)
+		return
+	}
+
+	log.Printf("INFO: Project created successfully, ID: %d, Name: %s", id, input.Name)
+	sendJSON(w, map[string]any{"id": fmt.Sprintf("%d", id)})
+}
+
+// getProjectHandler retrieves single project by ID
+// GET /api/project?id=project_id
+func (a *API) getProjectHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	log.Printf("DEBUG: Retrieving project by ID: %s", id)
+
+	if id == "" {
+		log.Printf("WARN: Project ID not specified in request")
+		sendError(w, "id not specified", http.StatusBadRequest)
+		return
+	}
+
+	project, err := a.storage.GetProject(r.Context(), id)
+	if err != nil {
+		sendStorageError(w, err)
+		return
+	}
+
+	log.Printf("INFO: Project retrieved successfully, ID: %s", id)
+	sendJSON(w, project)
+}
+
+// updateProjectHandler renames, recolors, archives or unarchives the project
+// PUT /api/project {"id": "1", "name": "Работа", "color": "#3366ff", "archived": true}
+func (a *API) updateProjectHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("DEBUG: Received project update request")
+
+	var input models.Project
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		log.Printf("WARN: Invalid JSON in project update request: %v", err)
+		sendError(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if input.ID == "" {
+		log.Printf("WARN: Missing project ID in update request")
+		sendError(w, "id is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := a.storage.UpdateProject(r.Context(), &input); err != nil {
+		sendStorageError(w, err)
+		return
+	}
+
+	log.Printf("INFO: Project updated successfully, ID: %s", input.ID)
+	sendJSON(w, map[string]any{})
+}
+
+// deleteProjectHandler removes the project, its tasks are kept without project
+// DELETE /api/project?id=project_id
+func (a *API) deleteProjectHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	log.Printf("DEBUG: Deleting project, ID: %s", id)
+
+	if id == "" {
+		log.Printf("WARN: Project ID not specified in delete request")
+		sendError(w, "id not specified", http.StatusBadRequest)
+		return
+	}
+
+	if err := a.storage.DeleteProject(r.Context(), id); err != nil {
+		sendStorageError(w, err)
+		return
+	}
+
+	log.Printf("INFO: Project deleted successfully, ID: %s", id)
+	sendJSON(w, map[string]any{})
+}
+
+// moveTaskHandler moves task to another project
+// Empty project removes the task from its project
+// POST /api/task/move?id=task_id&project=project_id
+func (a *API) moveTaskHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	project := r.URL.Query().Get("project")
+	log.Printf("DEBUG: Moving task %s to project '%s'", id, project)
+
+	if id == "" {
+		log.Printf("WARN: Task ID not specified in move request")
+		sendError(w, "id not specified", http.StatusBadRequest)
+		return
+	}
+
+	_, after, err := a.storage.MoveTask(r.Context(), id, project, a.clientID(r))
+	if err != nil {
+		sendStorageError(w, err)
+		return
+	}
+
+	log.Printf("INFO: Task %s moved to project '%s'", id, project)
+	w.Header().Set("ETag", etag(after.Version))
+	sendJSON(w, map[string]any{})
+}
@@ -2,11 +2,17 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"net/netip"
+	"strconv"
 	"strings"
 	"time"
+	"todo/pkg/attachments"
+	"todo/pkg/backup"
 	"todo/pkg/db"
 	"todo/pkg/models"
 
@@ -15,16 +21,40 @@ import (
 
 const dateLayout = "20060102"
 const limit int = 50
+const batchSize int = 500
+
+// Config holds tunable API settings
+type Config struct {
+	MaxPageSize  int // upper bound for the page size a client may request
+	MaxBatchSize int // upper bound for the number of operations in one batch
+
+	// TrustedProxies - networks of reverse proxies whose X-Forwarded-For and X-Real-IP headers are trusted,
+	// without them the audit trail records the address of the connection
+	TrustedProxies []netip.Prefix
+}
 
 type API struct {
-	storage *db.Storage
-	router  http.Handler
+	storage     *db.Storage
+	backups     *backup.Manager
+	attachments *attachments.Manager
+	router      http.Handler
+	config      Config
 }
 
 // NewAPI creates a new instance of the API
-func NewAPI(storage *db.Storage) *API {
+func NewAPI(storage *db.Storage, backups *backup.Manager, files *attachments.Manager, config Config) *API {
+	if config.MaxPageSize < limit {
+		config.MaxPageSize = limit
+	}
+	if config.MaxBatchSize <= 0 {
+		config.MaxBatchSize = batchSize
+	}
+
 	api := &API{
-		storage: storage,
+		storage:     storage,
+		backups:     backups,
+		attachments: files,
+		config:      config,
 	}
 
 	api.setupRouter()
@@ -41,19 +71,81 @@ func (a *API) setupRouter() {
 	// Public routes (no authentication required)
 	r.Group(func(r chi.Router) {
 		r.Get("/api/nextdate", nextDayHandler)
-		r.Post("/api/signin", SignInHandler)
+		r.Get("/api/health", a.healthHandler)
+		r.Post("/api/signin", a.SignInHandler)
 	})
 
 	// Protected routes (require authentication)
 	r.Group(func(r chi.Router) {
-		r.Use(authMiddleware)
-
-		r.Post("/api/task", a.addTaskHandler)
-		r.Get("/api/task", a.getTaskHandler)
-		r.Put("/api/task", a.updateTaskHandler)
-		r.Get("/api/tasks", a.tasksHandler)
-		r.Post("/api/task/done", a.doneTaskHandler)
-		r.Delete("/api/task", a.deleteTaskHandler)
+		r.Use(a.authMiddleware)
+
+		// Own account, available to every role
+		r.Get("/api/user", a.currentUserHandler)
+		r.Post("/api/user/password", a.changePasswordHandler)
+
+		// Task routes, read-only accounts may only read
+		r.Group(func(r chi.Router) {
+			r.Use(restrictReadOnly)
+
+			r.Post("/api/task", a.addTaskHandler)
+			r.Get("/api/task", a.getTaskHandler)
+			r.Put("/api/task", a.updateTaskHandler)
+			r.Get("/api/tasks", a.tasksHandler)
+			r.Get("/api/tasks/plan", a.planHandler)
+			r.Get("/api/tasks/inbox", a.inboxHandler)
+			r.Post("/api/tasks/batch", a.batchHandler)
+			r.Post("/api/task/done", a.doneTaskHandler)
+			r.Post("/api/task/reopen", a.reopenTaskHandler)
+			r.Post("/api/task/snooze", a.snoozeTaskHandler)
+			r.Delete("/api/task", a.deleteTaskHandler)
+			r.Get("/api/task/history", a.historyHandler)
+			r.Post("/api/task/revert", a.revertTaskHandler)
+			r.Post("/api/task/move", a.moveTaskHandler)
+			r.Get("/api/task/shares", a.sharesHandler)
+			r.Post("/api/task/share", a.shareTaskHandler)
+			r.Delete("/api/task/share", a.unshareTaskHandler)
+			r.Get("/api/task/attachments", a.attachmentsHandler)
+			r.Post("/api/task/attachments", a.uploadAttachmentHandler)
+			r.Get("/api/attachment", a.downloadAttachmentHandler)
+			r.Delete("/api/attachment", a.deleteAttachmentHandler)
+
+			r.Get("/api/completions", a.completionsHandler)
+
+			r.Get("/api/checklist", a.checklistHandler)
+			r.Post("/api/checklist", a.addChecklistItemHandler)
+			r.Put("/api/checklist", a.updateChecklistItemHandler)
+			r.Delete("/api/checklist", a.deleteChecklistItemHandler)
+			r.Post("/api/checklist/toggle", a.toggleChecklistItemHandler)
+			r.Post("/api/checklist/reorder", a.reorderChecklistHandler)
+
+			r.Get("/api/projects", a.projectsHandler)
+			r.Post("/api/project", a.addProjectHandler)
+			r.Get("/api/project", a.getProjectHandler)
+			r.Put("/api/project", a.updateProjectHandler)
+			r.Delete("/api/project", a.deleteProjectHandler)
+
+			r.Get("/api/tags", a.tagsHandler)
+			r.Post("/api/tags/rename", a.renameTagHandler)
+			r.Post("/api/tags/merge", a.mergeTagsHandler)
+
+			r.Get("/api/trash", a.trashHandler)
+			r.Post("/api/trash/restore", a.restoreTaskHandler)
+			r.Delete("/api/trash", a.purgeTrashHandler)
+		})
+
+		// Admin routes
+		r.Group(func(r chi.Router) {
+			r.Use(requireRole(models.RoleAdmin))
+
+			r.Get("/api/admin/users", a.usersHandler)
+			r.Post("/api/admin/user", a.addUserHandler)
+			r.Put("/api/admin/user", a.setRoleHandler)
+			r.Delete("/api/admin/user", a.deleteUserHandler)
+
+			r.Post("/api/admin/backup", a.backupHandler)
+			r.Get("/api/admin/backups", a.backupsHandler)
+			r.Post("/api/admin/restore", a.restoreBackupHandler)
+		})
 	})
 
 	log.Printf("INFO: Router initialized with authentication middleware")
@@ -67,9 +159,9 @@ func (a *API) Router() http.Handler {
 }
 
 // authMiddleware - adapter for chi middleware
-func authMiddleware(next http.Handler) http.Handler {
+func (a *API) authMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		AuthMiddleware(next.ServeHTTP)(w, r)
+		a.AuthMiddleware(next.ServeHTTP)(w, r)
 	})
 }
 
@@ -125,30 +217,17 @@ func (a *API) addTaskHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if input.Title == "" {
-		log.Printf("WARN: Empty title in task creation request")
-		sendError(w, "the title is empty", http.StatusBadRequest)
-		return
-	}
-
-	date, err := NormalizeDate(input.Date, input.Repeat)
+	input.ID, input.Version = "", ""
+	task, err := taskFromInput(input)
 	if err != nil {
-		log.Printf("WARN: Date normalization failed: %v", err)
+		log.Printf("WARN: Invalid task in creation request: %v", err)
 		sendError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	task := models.Task{
-		Date:    date,
-		Title:   input.Title,
-		Comment: input.Comment,
-		Repeat:  input.Repeat,
-	}
-
-	id, err := a.storage.AddTask(&task)
+	id, err := a.storage.AddTask(r.Context(), task, a.clientID(r))
 	if err != nil {
-		log.Printf("ERROR: Failed to save task to database: %v", err)
-		sendError(w, "saving error", http.StatusInternalServerError)
+		sendStorageError(w, err)
 		return
 	}
 
@@ -156,33 +235,102 @@ func (a *API) addTaskHandler(w http.ResponseWriter, r *http.Request) {
 	sendJSON(w, map[string]any{"id": fmt.Sprintf("%d", id)})
 }
 
-// tasksHandler retrieves tasks list with optional search
-// GET /api/tasks?search=query
+// tasksHandler retrieves tasks list with optional search and pagination
+// Search of the form #name filters by tag
+// Order is date (date, then priority) or priority (priority, then date)
+// Scope assigned lists tasks assigned to the user, shared - tasks shared with the user,
+// without scope own, assigned and shared tasks are listed together
+// Undated tasks are listed in the inbox only, tasks starting after today are hidden without deferred,
+// overdue lists tasks due before today, status done lists completed one-time tasks whatever their dates are
+// GET /api/tasks?search=query&tag=name&project=id&scope=assigned&status=done&deferred=true&overdue=true&order=date&limit=N&cursor=next
 func (a *API) tasksHandler(w http.ResponseWriter, r *http.Request) {
+	a.listTasks(w, r, false)
+}
 
+// inboxHandler retrieves undated tasks waiting to be scheduled
+// Accepts the same parameters as GET /api/tasks
+// GET /api/tasks/inbox?search=query&tag=name&project=id&order=priority&limit=N&cursor=next
+func (a *API) inboxHandler(w http.ResponseWriter, r *http.Request) {
+	a.listTasks(w, r, true)
+}
+
+// listTasks sends tasks of the agenda or of the inbox matching query parameters of the request
+func (a *API) listTasks(w http.ResponseWriter, r *http.Request, inbox bool) {
 	search := r.URL.Query().Get("search")
-	log.Printf("DEBUG: Retrieving tasks, search: '%s'", search)
+	log.Printf("DEBUG: Retrieving tasks, search: '%s', inbox: %t", search, inbox)
 
-	var tasks db.TasksResp
-	var err error
+	pageSize, err := a.pageSize(r)
+	if err != nil {
+		log.Printf("WARN: Invalid page size: %v", err)
+		sendError(w, "invalid limit", http.StatusBadRequest)
+		return
+	}
+	deferred, err := boolParam(r, "deferred")
+	if err != nil {
+		sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	overdue, err := boolParam(r, "overdue")
+	if err != nil {
+		sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	if search == "" {
-		tasks, err = a.storage.GetTasks(limit)
-	} else {
-		if date, err2 := time.Parse("02.01.2006", search); err2 == nil {
-			y, m, d := date.Date()
-			date := fmt.Sprintf("%d%02d%02d", y, m, d)
-			log.Printf("DEBUG: Searching tasks by date: %s", date)
-			tasks, err = a.storage.GetTasksByDate(limit, date)
+	filter := db.TaskFilter{
+		Limit:   pageSize,
+		Tag:     r.URL.Query().Get("tag"),
+		Project: r.URL.Query().Get("project"),
+		Scope:   r.URL.Query().Get("scope"),
+		Status:  r.URL.Query().Get("status"),
+		Order:   r.URL.Query().Get("order"),
+		Inbox:   inbox,
+
+		Today:    time.Now().Format(dateLayout),
+		Deferred: deferred,
+		Overdue:  overdue,
+	}
+	if filter.Order != "" && filter.Order != db.OrderByDate && filter.Order != db.OrderByPriority {
+		log.Printf("WARN: Invalid task order: %s", filter.Order)
+		sendError(w, "order must be date or priority", http.StatusBadRequest)
+		return
+	}
+	if filter.Status != "" && filter.Status != db.StatusActive && filter.Status != db.StatusDone {
+		log.Printf("WARN: Invalid task status: %s", filter.Status)
+		sendError(w, "status must be active or done", http.StatusBadRequest)
+		return
+	}
+	if filter.Scope != "" && filter.Scope != db.ScopeAssigned && filter.Scope != db.ScopeShared {
+		log.Printf("WARN: Invalid task scope: %s", filter.Scope)
+		sendError(w, "scope must be assigned or shared", http.StatusBadRequest)
+		return
+	}
+
+	if s := r.URL.Query().Get("cursor"); s != "" {
+		cursor, err := db.DecodeCursor(s)
+		if err != nil {
+			log.Printf("WARN: Invalid cursor: %s", s)
+			sendError(w, "invalid cursor", http.StatusBadRequest)
+			return
+		}
+		filter.After = cursor
+	}
+
+	if search != "" {
+		if strings.HasPrefix(search, "#") {
+			filter.Tag = search
+			log.Printf("DEBUG: Searching tasks by tag: '%s'", search)
+		} else if date, err := time.Parse("02.01.2006", search); err == nil {
+			filter.Date = date.Format(dateLayout)
+			log.Printf("DEBUG: Searching tasks by date: %s", filter.Date)
 		} else {
+			filter.Search = search
 			log.Printf("DEBUG: Searching tasks by title/comment: '%s'", search)
-			tasks, err = a.storage.GetTasksByTitle(limit, search)
 		}
 	}
 
+	tasks, err := a.storage.GetTasks(r.Context(), filter)
 	if err != nil {
-		log.Printf("ERROR: Failed to retrieve tasks: %v", err)
-		sendError(w, "failed to get tasks", http.StatusInternalServerError)
+		sendStorageError(w, err)
 		return
 	}
 
@@ -190,6 +338,35 @@ func (a *API) tasksHandler(w http.ResponseWriter, r *http.Request) {
 	sendJSON(w, tasks)
 }
 
+// boolParam reads optional boolean query parameter, absent parameter is false
+func boolParam(r *http.Request, name string) (bool, error) {
+	s := r.URL.Query().Get(name)
+	if s == "" {
+		return false, nil
+	}
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		log.Printf("WARN: Invalid %s parameter: %s", name, s)
+		return false, fmt.Errorf("%s must be true or false", name)
+	}
+	return v, nil
+}
+
+// pageSize reads client-chosen page size from the limit query parameter
+// Falls back to the default and caps the value at configured maximum
+func (a *API) pageSize(r *http.Request) (int, error) {
+	s := r.URL.Query().Get("limit")
+	if s == "" {
+		return limit, nil
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid limit: %s", s)
+	}
+	return min(n, a.config.MaxPageSize), nil
+}
+
 // getTaskHandler retrieves single task by ID
 // GET /api/task?id=task_id
 func (a *API) getTaskHandler(w http.ResponseWriter, r *http.Request) {
@@ -198,27 +375,24 @@ func (a *API) getTaskHandler(w http.ResponseWriter, r *http.Request) {
 
 	if id == "" {
 		log.Printf("WARN: Task ID not specified in request")
-		http.Error(w, `{"error": "id not specified"}`, http.StatusBadRequest)
+		sendError(w, "id not specified", http.StatusBadRequest)
 		return
 	}
 
-	task, err := a.storage.GetTask(id)
+	task, err := a.storage.GetTask(r.Context(), id)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			log.Printf("WARN: Task not found, ID: %s", id)
-			sendError(w, "task not found", http.StatusNotFound)
-		} else {
-			log.Printf("ERROR: Database error retrieving task %s: %v", id, err)
-			sendError(w, "internal server error", http.StatusInternalServerError)
-		}
+		sendStorageError(w, err)
 		return
 	}
 
 	log.Printf("INFO: Task retrieved successfully, ID: %s", id)
+	w.Header().Set("ETag", etag(task.Version))
 	sendJSON(w, task)
 }
 
 // updateTaskHandler updates existing task
+// The edited version must be given in If-Match header (ETag of GET /api/task) or in version field
+// Outdated version is rejected with 412 for If-Match and 409 for the field
 // PUT /api/task
 func (a *API) updateTaskHandler(w http.ResponseWriter, r *http.Request) {
 	log.Printf("DEBUG: Received task update request")
@@ -236,110 +410,231 @@ func (a *API) updateTaskHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if input.Title == "" {
-		log.Printf("WARN: Empty title in task update request, ID: %s", input.ID)
-		sendError(w, "the title is empty", http.StatusBadRequest)
-		return
+	if updatesUndated(input) {
+		current, err := a.storage.GetTask(r.Context(), input.ID)
+		if err != nil {
+			sendStorageError(w, err)
+			return
+		}
+		keepUndated(&input, current)
 	}
 
-	date, err := NormalizeDate(input.Date, input.Repeat)
+	task, err := taskFromInput(input)
 	if err != nil {
-		log.Printf("WARN: Date normalization failed for task %s: %v", input.ID, err)
+		log.Printf("WARN: Invalid task in update request, ID: %s: %v", input.ID, err)
 		sendError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	task := models.Task{
-		ID:      input.ID,
-		Date:    date,
-		Title:   input.Title,
-		Comment: input.Comment,
-		Repeat:  input.Repeat,
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch != "" {
+		task.Version = parseETag(ifMatch)
+	} else if task.Version == "" {
+		log.Printf("WARN: Task version not specified in update request, ID: %s", input.ID)
+		sendError(w, errVersionRequired.Error(), http.StatusPreconditionRequired)
+		return
 	}
 
-	err = a.storage.UpdateTask(&task)
-	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			log.Printf("WARN: Task not found for update, ID: %s", input.ID)
-			sendError(w, "task not found", http.StatusNotFound)
-		} else {
-			log.Printf("ERROR: Database error updating task %s: %v", input.ID, err)
-			sendError(w, "internal server error", http.StatusInternalServerError)
+	before, err := a.storage.UpdateTask(r.Context(), task, models.ActionUpdate, a.clientID(r))
+	if errors.Is(err, db.ErrConflict) && before != nil {
+		status := http.StatusConflict
+		if ifMatch != "" {
+			status = http.StatusPreconditionFailed
 		}
+		sendVersionConflict(w, err, before, status)
+		return
+	}
+	if err != nil {
+		sendStorageError(w, err)
 		return
 	}
 
-	log.Printf("INFO: Task updated successfully, ID: %s, Title: %s", input.ID, input.Title)
+	log.Printf("INFO: Task updated successfully, ID: %s, Title: %s, version: %s", input.ID, input.Title, task.Version)
+	w.Header().Set("ETag", etag(task.Version))
 	sendJSON(w, map[string]any{})
 }
 
-// doneTaskHandler marks task as done and handles recurrence
+// taskFromInput validates task fields sent by the client and normalizes the date
+// Returned errors are *db.ValidationError
+func taskFromInput(input models.Task) (*models.Task, error) {
+	if input.Title == "" {
+		return nil, &db.ValidationError{Message: "the title is empty"}
+	}
+
+	date, err := NormalizeDate(input.Date, input.Repeat)
+	if err != nil {
+		return nil, &db.ValidationError{Message: err.Error()}
+	}
+
+	start, err := normalizeStart(input.Start, input.Date, date)
+	if err != nil {
+		return nil, err
+	}
+
+	priority, err := normalizePriority(input.Priority)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.Task{
+		ID:        input.ID,
+		Date:      date,
+		Start:     start,
+		Title:     input.Title,
+		Comment:   input.Comment,
+		Repeat:    input.Repeat,
+		Version:   input.Version,
+		Tags:      input.Tags,
+		Project:   input.Project,
+		Priority:  priority,
+		BlockedBy: input.BlockedBy,
+		Assignee:  input.Assignee,
+	}, nil
+}
+
+// updatesUndated reports whether the update input has no date, which depends on the current task
+func updatesUndated(input models.Task) bool {
+	return input.Date == "" && input.Repeat == ""
+}
+
+// keepUndated leaves a task without date undated when the update input has no date either
+// Undated tasks are listed without the date field, so a task sent back as it was read stays in the inbox.
+// A dated task updated without date still moves to today
+func keepUndated(input *models.Task, current *models.Task) {
+	if updatesUndated(*input) && current.Date == "" {
+		input.Date = dateSomeday
+	}
+}
+
+// normalizeStart validates start date sent by the client for the task due on date
+// When normalization moved the requested due date, start moves by the same number of days
+// Start after the due date is rejected, undated tasks may have a start date
+func normalizeStart(start, requested, date string) (string, error) {
+	if start == "" {
+		return "", nil
+	}
+	if _, err := time.Parse(dateLayout, start); err != nil {
+		return "", &db.ValidationError{Field: "start", Message: "invalid start date format"}
+	}
+	if date == "" {
+		return start, nil
+	}
+
+	if _, err := time.Parse(dateLayout, requested); err == nil {
+		start, err = db.ShiftStart(start, requested, date)
+		if err != nil {
+			return "", err
+		}
+	}
+	if start > date {
+		return "", &db.ValidationError{Field: "start", Message: "start date must not be after the due date"}
+	}
+	return start, nil
+}
+
+// normalizePriority validates priority sent by the client and returns it as P1-P4
+// Accepts lower case and bare numbers, empty priority is kept empty
+func normalizePriority(priority string) (string, error) {
+	p := strings.ToUpper(strings.TrimSpace(priority))
+	if p == "" {
+		return "", nil
+	}
+	if !strings.HasPrefix(p, "P") {
+		p = "P" + p
+	}
+	switch p {
+	case "P1", "P2", "P3", "P4":
+		return p, nil
+	}
+	return "", &db.ValidationError{Field: "priority", Message: "priority must be one of P1, P2, P3, P4"}
+}
+
+// doneTaskHandler marks task as done, handles recurrence and records completion
 // POST /api/task/done?id=task_id
+// Body is optional: {"note": "..."}
 func (a *API) doneTaskHandler(w http.ResponseWriter, r *http.Request) {
 	id := r.URL.Query().Get("id")
 	log.Printf("DEBUG: Marking task as done, ID: %s", id)
 
 	if id == "" {
 		log.Printf("WARN: Task ID not specified in done request")
-		http.Error(w, `{"error": "id not specified"}`, http.StatusBadRequest)
+		sendError(w, "id not specified", http.StatusBadRequest)
+		return
+	}
+
+	var input models.DoneInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil && err != io.EOF {
+		log.Printf("WARN: Invalid JSON in done request: %v", err)
+		sendError(w, "invalid JSON", http.StatusBadRequest)
 		return
 	}
 
-	task, err := a.storage.GetTask(id)
+	before, after, err := a.storage.CompleteTask(r.Context(), id, input.Note, a.clientID(r), NextDate)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			log.Printf("WARN: Task not found for done operation, ID: %s", id)
-			sendError(w, "task not found", http.StatusNotFound)
-		} else {
-			log.Printf("ERROR: Database error retrieving task %s for done: %v", id, err)
-			sendError(w, "internal server error", http.StatusInternalServerError)
-		}
+		sendStorageError(w, err)
 		return
 	}
 
-	if task.Repeat == "" {
-		// One-time task - delete it
-		err = a.storage.DeleteTask(id)
-		if err != nil {
-			if strings.Contains(err.Error(), "not found") {
-				log.Printf("WARN: Task not found for deletion, ID: %s", id)
-				sendError(w, "task not found", http.StatusNotFound)
-			} else {
-				log.Printf("ERROR: Database error deleting task %s: %v", id, err)
-				sendError(w, "internal server error", http.StatusInternalServerError)
-			}
-			return
-		}
-		log.Printf("INFO: One-time task completed and deleted, ID: %s", id)
-		sendJSON(w, map[string]any{})
+	if after.CompletedAt != "" {
+		log.Printf("INFO: One-time task completed, ID: %s", id)
+	} else {
+		log.Printf("INFO: Recurring task completed, ID: %s, next date: %s, rule: %s", id, after.Date, before.Repeat)
+	}
+	sendJSON(w, map[string]any{})
+}
+
+// reopenTaskHandler returns completed one-time task to the task lists
+// POST /api/task/reopen?id=task_id
+func (a *API) reopenTaskHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	log.Printf("DEBUG: Reopening task, ID: %s", id)
+
+	if id == "" {
+		log.Printf("WARN: Task ID not specified in reopen request")
+		sendError(w, "id not specified", http.StatusBadRequest)
 		return
 	}
 
-	// Recurring task - calculate next date
-	now := time.Now()
-	next, err := NextDate(now, task.Date, task.Repeat)
-	if err != nil {
-		log.Printf("WARN: Next date calculation failed for recurring task %s: %v", id, err)
-		sendError(w, err.Error(), http.StatusBadRequest)
+	if _, _, err := a.storage.ReopenTask(r.Context(), id, a.clientID(r)); err != nil {
+		sendStorageError(w, err)
 		return
 	}
 
-	err = a.storage.UpdateTaskDate(task.ID, next)
+	log.Printf("INFO: Task reopened, ID: %s", id)
+	sendJSON(w, map[string]any{})
+}
+
+// snoozeTaskHandler moves task to a later date by a relative offset or to the given date
+// Recurring task moves the current occurrence only, the series stays in place
+// POST /api/task/snooze?id=task_id {"to": "+1d"}
+func (a *API) snoozeTaskHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	log.Printf("DEBUG: Snoozing task, ID: %s", id)
+
+	if id == "" {
+		log.Printf("WARN: Task ID not specified in snooze request")
+		sendError(w, "id not specified", http.StatusBadRequest)
+		return
+	}
+
+	var input models.SnoozeInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		log.Printf("WARN: Invalid JSON in snooze request: %v", err)
+		sendError(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	_, after, err := a.storage.SnoozeTask(r.Context(), id, input.To, a.clientID(r), SnoozeDate)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			log.Printf("WARN: Task not found for date update, ID: %s", task.ID)
-			sendError(w, "task not found", http.StatusNotFound)
-		} else {
-			log.Printf("ERROR: Database error updating task date %s: %v", task.ID, err)
-			sendError(w, "internal server error", http.StatusInternalServerError)
-		}
+		sendStorageError(w, err)
 		return
 	}
-	log.Printf("INFO: Recurring task completed, ID: %s, next date: %s, rule: %s", task.ID, next, task.Repeat)
-	sendJSON(w, map[string]any{})
+
+	log.Printf("INFO: Task snoozed, ID: %s, new date: %s", id, after.Date)
+	sendJSON(w, after)
 }
 
-// deleteTaskHandler removes task from scheduler
+// deleteTaskHandler moves task from scheduler to the trash
 // DELETE /api/task?id=task_id
 func (a *API) deleteTaskHandler(w http.ResponseWriter, r *http.Request) {
 	id := r.URL.Query().Get("id")
@@ -347,19 +642,12 @@ func (a *API) deleteTaskHandler(w http.ResponseWriter, r *http.Request) {
 
 	if id == "" {
 		log.Printf("WARN: Task ID not specified in delete request")
-		http.Error(w, `{"error": "id not specified"}`, http.StatusBadRequest)
+		sendError(w, "id not specified", http.StatusBadRequest)
 		return
 	}
 
-	err := a.storage.DeleteTask(id)
-	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			log.Printf("WARN: Task not found for deletion, ID: %s", id)
-			sendError(w, "task not found", http.StatusNotFound)
-		} else {
-			log.Printf("ERROR: Database error deleting task %s: %v", id, err)
-			sendError(w, "internal server error", http.StatusInternalServerError)
-		}
+	if _, err := a.storage.DeleteTask(r.Context(), id, a.clientID(r)); err != nil {
+		sendStorageError(w, err)
 		return
 	}
 
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"todo/pkg/models"
+)
+
+// sharesHandler lists users the task is shared with
+// GET /api/task/shares?id=task_id
+func (a *API) sharesHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	log.Printf("DEBUG: Retrieving shares of task %s", id)
+
+	if id == "" {
+		log.Printf("WARN: Task ID not specified in shares request")
+		sendError(w, "id not specified", http.StatusBadRequest)
+		return
+	}
+
+	shares, err := a.storage.GetShares(r.Context(), id)
+	if err != nil {
+		sendStorageError(w, err)
+		return
+	}
+
+	log.Printf("INFO: Retrieved %d shares of task %s", len(shares), id)
+	sendJSON(w, map[string]any{"shares": shares})
+}
+
+// shareTaskHandler shares the task with a user, sharing again changes the permission
+// Only the owner of the task shares it
+// POST /api/task/share?id=task_id {"login": "anna", "permission": "edit"}
+func (a *API) shareTaskHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	log.Printf("DEBUG: Received share request, task: %s", id)
+
+	if id == "" {
+		log.Printf("WARN: Task ID not specified in share request")
+		sendError(w, "id not specified", http.StatusBadRequest)
+		return
+	}
+
+	var input models.Share
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		log.Printf("WARN: Invalid JSON in share request: %v", err)
+		sendError(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if err := a.storage.ShareTask(r.Context(), id, &input); err != nil {
+		sendStorageError(w, err)
+		return
+	}
+
+	log.Printf("INFO: Task %s shared with %s, permission: %s", id, input.Login, input.Permission)
+	sendJSON(w, input)
+}
+
+// unshareTaskHandler revokes access of a user to the task
+// The owner revokes any share, a user may leave a task shared with them
+// DELETE /api/task/share?id=task_id&login=anna
+func (a *API) unshareTaskHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	login := r.URL.Query().Get("login")
+	log.Printf("DEBUG: Received unshare request, task: %s, login: %s", id, login)
+
+	if id == "" || login == "" {
+		log.Printf("WARN: Task ID or login not specified in unshare request")
+		sendError(w, "id and login are required", http.StatusBadRequest)
+		return
+	}
+
+	if err := a.storage.UnshareTask(r.Context(), id, login); err != nil {
+		sendStorageError(w, err)
+		return
+	}
+
+	log.Printf("INFO: Share of task %s for %s revoked", id, login)
+	sendJSON(w, map[string]any{})
+}
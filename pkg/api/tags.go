@@ -0,0 +1,74 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"todo/pkg/models"
+)
+
+// tagsHandler lists tags of active tasks with task counts
+// GET /api/tags
+func (a *API) tagsHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("DEBUG: Retrieving tags")
+
+	tags, err := a.storage.GetTags(r.Context())
+	if err != nil {
+		sendStorageError(w, err)
+		return
+	}
+
+	log.Printf("INFO: Retrieved %d tags", len(tags))
+	sendJSON(w, map[string]any{"tags": tags})
+}
+
+// renameTagHandler renames tag on all tasks
+// Responds 409 when the new name is taken, use merge instead
+// POST /api/tags/rename {"from": "old", "to": "new"}
+func (a *API) renameTagHandler(w http.ResponseWriter, r *http.Request) {
+	var input models.TagRenameInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		log.Printf("WARN: Invalid JSON in tag rename request: %v", err)
+		sendError(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	log.Printf("DEBUG: Renaming tag %s to %s", input.From, input.To)
+
+	if input.From == "" || input.To == "" {
+		sendError(w, "from and to are required", http.StatusBadRequest)
+		return
+	}
+
+	if err := a.storage.RenameTag(r.Context(), input.From, input.To); err != nil {
+		sendStorageError(w, err)
+		return
+	}
+
+	log.Printf("INFO: Tag %s renamed to %s", input.From, input.To)
+	sendJSON(w, map[string]any{})
+}
+
+// mergeTagsHandler moves tasks of several tags to one tag and removes the others
+// POST /api/tags/merge {"from": ["a", "b"], "to": "c"}
+func (a *API) mergeTagsHandler(w http.ResponseWriter, r *http.Request) {
+	var input models.TagMergeInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		log.Printf("WARN: Invalid JSON in tag merge request: %v", err)
+		sendError(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	log.Printf("DEBUG: Merging tags %v into %s", input.From, input.To)
+
+	if len(input.From) == 0 || input.To == "" {
+		sendError(w, "from and to are required", http.StatusBadRequest)
+		return
+	}
+
+	if err := a.storage.MergeTags(r.Context(), input.From, input.To); err != nil {
+		sendStorageError(w, err)
+		return
+	}
+
+	log.Printf("INFO: Tags %v merged into %s", input.From, input.To)
+	sendJSON(w, map[string]any{})
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"time"
+)
+
+// trashHandler lists tasks in the trash, most recently deleted first
+// GET /api/trash?limit=N
+func (a *API) trashHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("DEBUG: Retrieving trash")
+
+	n, err := a.pageSize(r)
+	if err != nil {
+		log.Printf("WARN: Invalid page size: %v", err)
+		sendError(w, "invalid limit", http.StatusBadRequest)
+		return
+	}
+
+	tasks, err := a.storage.GetTrash(r.Context(), n)
+	if err != nil {
+		sendStorageError(w, err)
+		return
+	}
+
+	log.Printf("INFO: Retrieved %d tasks from trash", len(tasks.Tasks))
+	sendJSON(w, tasks)
+}
+
+// restoreTaskHandler moves task from the trash back to the scheduler
+// POST /api/trash/restore?id=task_id
+func (a *API) restoreTaskHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	log.Printf("DEBUG: Restoring task from trash, ID: %s", id)
+
+	if id == "" {
+		log.Printf("WARN: Task ID not specified in restore request")
+		sendError(w, "id not specified", http.StatusBadRequest)
+		return
+	}
+
+	if err := a.storage.RestoreTask(r.Context(), id, a.clientID(r)); err != nil {
+		sendStorageError(w, err)
+		return
+	}
+
+	log.Printf("INFO: Task restored from trash, ID: %s", id)
+	sendJSON(w, map[string]any{})
+}
+
+// purgeTrashHandler permanently removes task from the trash
+// Without id the whole trash is emptied
+// DELETE /api/trash?id=task_id
+func (a *API) purgeTrashHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+
+	if id == "" {
+		log.Printf("DEBUG: Emptying trash")
+		count, err := a.storage.PurgeTrash(r.Context(), time.Now())
+		if err != nil {
+			sendStorageError(w, err)
+			return
+		}
+		a.cleanupAttachments(r)
+		log.Printf("INFO: Trash emptied, %d tasks purged", count)
+		sendJSON(w, map[string]any{"purged": count})
+		return
+	}
+
+	log.Printf("DEBUG: Purging task from trash, ID: %s", id)
+	err := a.storage.PurgeTask(r.Context(), id)
+	if err != nil {
+		sendStorageError(w, err)
+		return
+	}
+
+	a.cleanupAttachments(r)
+	log.Printf("INFO: Task purged from trash, ID: %s", id)
+	sendJSON(w, map[string]any{"purged": 1})
+}
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"todo/pkg/db"
+	"todo/pkg/models"
+)
+
+// minPasswordLength - shortest accepted password of a registered user
+const minPasswordLength = 8
+
+// validatePassword checks password of a new account or a password change
+func validatePassword(password string) error {
+	if len([]rune(password)) < minPasswordLength {
+		return &db.ValidationError{Field: "password", Message: fmt.Sprintf("password must be at least %d characters long", minPasswordLength)}
+	}
+	return nil
+}
+
+// currentUserHandler retrieves the signed in account
+// GET /api/user
+func (a *API) currentUserHandler(w http.ResponseWriter, r *http.Request) {
+	sendJSON(w, db.UserFrom(r.Context()))
+}
+
+// changePasswordHandler changes password of the signed in account
+// Tokens issued before the change stop working, the user signs in again
+// POST /api/user/password {"password": "new secret"}
+func (a *API) changePasswordHandler(w http.ResponseWriter, r *http.Request) {
+	user := db.UserFrom(r.Context())
+	log.Printf("DEBUG: Received password change request for user %s", user.Login)
+
+	var input models.UserInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		log.Printf("WARN: Invalid JSON in password change request: %v", err)
+		sendError(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if err := validatePassword(input.Password); err != nil {
+		sendStorageError(w, err)
+		return
+	}
+
+	hash, err := hashPassword(input.Password)
+	if err != nil {
+		sendStorageError(w, err)
+		return
+	}
+	if err := a.storage.SetPassword(r.Context(), user.ID, hash); err != nil {
+		sendStorageError(w, err)
+		return
+	}
+
+	log.Printf("INFO: Password of user %s changed", user.Login)
+	sendJSON(w, map[string]any{})
+}
+
+// usersHandler lists accounts
+// GET /api/admin/users
+func (a *API) usersHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("DEBUG: Retrieving users")
+
+	users, err := a.storage.GetUsers(r.Context())
+	if err != nil {
+		sendStorageError(w, err)
+		return
+	}
+
+	log.Printf("INFO: Retrieved %d users", len(users))
+	sendJSON(w, map[string]any{"users": users})
+}
+
+// addUserHandler registers a new account
+// Responds 409 when the login is taken or TODO_PASSWORD is not set:
+// without it the admin account has no password to sign in with
+// Without role the account is an editor
+// POST /api/admin/user {"login": "anna", "password": "secret", "role": "editor"}
+func (a *API) addUserHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("DEBUG: Received user registration request")
+
+	if os.Getenv("TODO_PASSWORD") == "" {
+		log.Printf("WARN: User registration rejected, TODO_PASSWORD not set")
+		sendError(w, "set TODO_PASSWORD before registering users", http.StatusConflict)
+		return
+	}
+
+	var input models.UserInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		log.Printf("WARN: Invalid JSON in user registration request: %v", err)
+		sendError(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if err := validatePassword(input.Password); err != nil {
+		sendStorageError(w, err)
+		return
+	}
+
+	hash, err := hashPassword(input.Password)
+	if err != nil {
+		sendStorageError(w, err)
+		return
+	}
+
+	user := &models.User{Login: input.Login, Role: input.Role, PasswordHash: hash}
+	if _, err := a.storage.AddUser(r.Context(), user); err != nil {
+		sendStorageError(w, err)
+		return
+	}
+
+	log.Printf("INFO: User registered, ID: %s, login: %s, role: %s", user.ID, user.Login, user.Role)
+	sendJSON(w, map[string]any{"id": user.ID})
+}
+
+// setRoleHandler changes role of the account
+// Tokens issued before the change stop working, the user signs in again
+// PUT /api/admin/user {"id": "2", "role": "readonly"}
+func (a *API) setRoleHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("DEBUG: Received role change request")
+
+	var input models.UserInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		log.Printf("WARN: Invalid JSON in role change request: %v", err)
+		sendError(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if input.ID == "" {
+		log.Printf("WARN: User ID not specified in role change request")
+		sendError(w, "id not specified", http.StatusBadRequest)
+		return
+	}
+	if input.ID == db.UserFrom(r.Context()).ID {
+		log.Printf("WARN: User %s tried to change own role", input.ID)
+		sendError(w, "cannot change own role", http.StatusBadRequest)
+		return
+	}
+
+	if err := a.storage.SetRole(r.Context(), input.ID, input.Role); err != nil {
+		sendStorageError(w, err)
+		return
+	}
+
+	log.Printf("INFO: Role of user %s changed to %s", input.ID, input.Role)
+	sendJSON(w, map[string]any{})
+}
+
+// deleteUserHandler removes the account with all its tasks, projects and files
+// DELETE /api/admin/user?id=user_id
+func (a *API) deleteUserHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	log.Printf("DEBUG: Deleting user, ID: %s", id)
+
+	if id == "" {
+		log.Printf("WARN: User ID not specified in delete request")
+		sendError(w, "id not specified", http.StatusBadRequest)
+		return
+	}
+	if id == db.UserFrom(r.Context()).ID {
+		log.Printf("WARN: User %s tried to delete own account", id)
+		sendError(w, "cannot delete own account", http.StatusBadRequest)
+		return
+	}
+
+	if err := a.storage.DeleteUser(r.Context(), id); err != nil {
+		sendStorageError(w, err)
+		return
+	}
+
+	a.cleanupAttachments(r)
+	log.Printf("INFO: User deleted, ID: %s", id)
+	sendJSON(w, map[string]any{})
+}
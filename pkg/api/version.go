@@ -0,0 +1,43 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"todo/pkg/db"
+	"todo/pkg/models"
+)
+
+// errVersionRequired is returned for updates that do not state the edited version
+var errVersionRequired = &db.ValidationError{Field: "version", Message: "required, send If-Match header or version field"}
+
+// etag formats task version as a strong entity tag
+func etag(version string) string {
+	return `"` + version + `"`
+}
+
+// parseETag extracts version from If-Match header value
+// Weak tags are accepted, "*" matches any version and yields empty string
+func parseETag(header string) string {
+	tag := strings.TrimSpace(header)
+	if tag == "*" {
+		return ""
+	}
+	tag = strings.TrimPrefix(tag, "W/")
+	return strings.Trim(tag, `"`)
+}
+
+// sendVersionConflict reports outdated task version along with the current server state
+// Responds {"error": "...", "task": {...}} so the client can merge and retry
+func sendVersionConflict(w http.ResponseWriter, err error, current *models.Task, status int) {
+	log.Printf("WARN: Update rejected: %v", err)
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Header().Set("ETag", etag(current.Version))
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{
+		"error": err.Error(),
+		"task":  current,
+	})
+}
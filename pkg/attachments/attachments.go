@@ -0,0 +1,228 @@
+package attachments
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"todo/pkg/db"
+	"todo/pkg/models"
+)
+
+// Errors returned when an upload does not fit into configured limits
+var (
+	// ErrTooLarge - file exceeds the per-file size limit
+	ErrTooLarge = errors.New("file is too large")
+	// ErrQuotaExceeded - file does not fit into the total attachments quota
+	ErrQuotaExceeded = errors.New("attachments quota exceeded")
+)
+
+// hashName matches names of stored files
+var hashName = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// Config holds attachment storage settings
+type Config struct {
+	Dir     string // directory with attachment files, created on first upload
+	MaxSize int64  // largest accepted file in bytes, zero means unlimited
+	Quota   int64  // total size of all attachments in bytes, zero means unlimited
+}
+
+// Manager keeps attachment files in a content-addressed directory
+// File of each attachment is named after its sha256, so equal uploads share one file.
+// Metadata lives in the database, a file is removed once no attachment refers to it
+type Manager struct {
+	storage *db.Storage
+	config  Config
+	mu      sync.Mutex // serializes file placement and removal with metadata changes
+}
+
+// NewManager creates a new instance of the attachments manager
+func NewManager(storage *db.Storage, config Config) *Manager {
+	return &Manager{storage: storage, config: config}
+}
+
+// Dir returns the attachments directory
+func (m *Manager) Dir() string {
+	return m.config.Dir
+}
+
+// path returns location of the file with the checksum
+// Files are spread over subdirectories named after the first two hex digits
+func (m *Manager) path(hash string) string {
+	return filepath.Join(m.config.Dir, hash[:2], hash)
+}
+
+// Add stores content read from r and attaches it to the task
+// a must have TaskID and Name set, Size, SHA256, ID and CreatedAt are filled in
+func (m *Manager) Add(ctx context.Context, a *models.Attachment, r io.Reader) error {
+	log.Printf("DEBUG: Storing attachment %s of task %s", a.Name, a.TaskID)
+
+	if err := os.MkdirAll(m.config.Dir, 0755); err != nil {
+		return fmt.Errorf("create attachments directory: %w", err)
+	}
+
+	// Content is written to a temporary file first, its name is known only after hashing
+	tmp, err := os.CreateTemp(m.config.Dir, ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	src := r
+	if m.config.MaxSize > 0 {
+		src = io.LimitReader(r, m.config.MaxSize+1)
+	}
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, h), src)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		log.Printf("ERROR: Failed to write attachment %s: %v", a.Name, err)
+		return err
+	}
+	if m.config.MaxSize > 0 && size > m.config.MaxSize {
+		log.Printf("WARN: Attachment %s exceeds limit of %d bytes", a.Name, m.config.MaxSize)
+		return fmt.Errorf("%w: limit is %d bytes", ErrTooLarge, m.config.MaxSize)
+	}
+	a.Size = size
+	a.SHA256 = hex.EncodeToString(h.Sum(nil))
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.config.Quota > 0 {
+		used, err := m.storage.AttachmentsSize(ctx)
+		if err != nil {
+			return err
+		}
+		// A file stored already takes no more space
+		stored, err := m.storage.AttachmentStored(ctx, a.SHA256)
+		if err != nil {
+			return err
+		}
+		if !stored && used+size > m.config.Quota {
+			log.Printf("WARN: Attachment %s does not fit into quota, used %d of %d bytes", a.Name, used, m.config.Quota)
+			return fmt.Errorf("%w: %d of %d bytes used", ErrQuotaExceeded, used, m.config.Quota)
+		}
+	}
+
+	dest := m.path(a.SHA256)
+	created := false
+	if _, err := os.Stat(dest); os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return err
+		}
+		if err := os.Rename(tmp.Name(), dest); err != nil {
+			return err
+		}
+		created = true
+	} else if err != nil {
+		return err
+	}
+
+	if _, err := m.storage.AddAttachment(ctx, a); err != nil {
+		if created {
+			os.Remove(dest)
+		}
+		return err
+	}
+
+	log.Printf("INFO: Attachment stored, ID: %s, sha256: %s, new file: %t", a.ID, a.SHA256, created)
+	return nil
+}
+
+// Open returns attachment metadata and its file, the caller closes the file
+func (m *Manager) Open(ctx context.Context, id string) (*models.Attachment, *os.File, error) {
+	a, err := m.storage.GetAttachment(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	f, err := os.Open(m.path(a.SHA256))
+	if os.IsNotExist(err) {
+		log.Printf("ERROR: File of attachment %s is missing: %s", id, a.SHA256)
+		return nil, nil, fmt.Errorf("file of attachment %s %w", id, db.ErrNotFound)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return a, f, nil
+}
+
+// Delete removes the attachment and its file when no other attachment shares it
+func (m *Manager) Delete(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	a, err := m.storage.DeleteAttachment(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	used, err := m.storage.AttachmentStored(ctx, a.SHA256)
+	if err != nil || used {
+		return err
+	}
+	if err := os.Remove(m.path(a.SHA256)); err != nil && !os.IsNotExist(err) {
+		log.Printf("ERROR: Failed to remove attachment file %s: %v", a.SHA256, err)
+		return err
+	}
+
+	log.Printf("INFO: Attachment file removed: %s", a.SHA256)
+	return nil
+}
+
+// Cleanup removes files no attachment of an existing task refers to,
+// e.g. after tasks were purged from the trash
+// Returns number of removed files
+func (m *Manager) Cleanup(ctx context.Context) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	hashes, err := m.storage.AttachmentHashes(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	dirs, err := os.ReadDir(m.config.Dir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, dir := range dirs {
+		if !dir.IsDir() || len(dir.Name()) != 2 {
+			continue
+		}
+		files, err := os.ReadDir(filepath.Join(m.config.Dir, dir.Name()))
+		if err != nil {
+			return removed, err
+		}
+		for _, f := range files {
+			if !hashName.MatchString(f.Name()) || hashes[f.Name()] {
+				continue
+			}
+			if err := os.Remove(m.path(f.Name())); err != nil {
+				log.Printf("ERROR: Failed to remove attachment file %s: %v", f.Name(), err)
+				return removed, err
+			}
+			removed++
+		}
+	}
+
+	if removed > 0 {
+		log.Printf("INFO: Removed %d unreferenced attachment files", removed)
+	}
+	return removed, nil
+}
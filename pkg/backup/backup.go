@@ -0,0 +1,360 @@
+package backup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"todo/pkg/db"
+)
+
+// nameLayout - timestamp format used in snapshot file names, same as scripts/backup.sh
+const nameLayout = "20060102_150405"
+
+// manifestExt - suffix of the checksum file written next to each snapshot
+// The file uses sha256sum format and can be checked with `sha256sum -c`
+const manifestExt = ".sha256"
+
+// Config holds backup directory and schedule settings
+type Config struct {
+	Dir      string        // directory where snapshots are kept, created on first backup
+	Interval time.Duration // period of automatic backups, zero disables them
+	Keep     int           // number of snapshots kept by retention, zero keeps all
+	MaxAge   time.Duration // age after which snapshots are removed by retention, zero keeps all
+}
+
+// Info describes a snapshot stored in the backup directory
+type Info struct {
+	Name      string `json:"name"`
+	Size      int64  `json:"size"`
+	SHA256    string `json:"sha256,omitempty"`
+	CreatedAt string `json:"created_at"`
+}
+
+// Status reports outcome of the most recent backup attempt
+type Status struct {
+	LastAt     string `json:"last_at,omitempty"`
+	LastStatus string `json:"last_status,omitempty"` // "ok" or "failed"
+	LastName   string `json:"last_name,omitempty"`
+	LastError  string `json:"last_error,omitempty"`
+	NextAt     string `json:"next_at,omitempty"`
+}
+
+// Manager creates and restores database snapshots in a backup directory
+type Manager struct {
+	storage *db.Storage
+	config  Config
+	mu      sync.Mutex // serializes snapshots and restores
+
+	statusMu sync.Mutex
+	status   Status
+}
+
+// NewManager creates a new instance of the backup manager
+func NewManager(storage *db.Storage, config Config) *Manager {
+	return &Manager{storage: storage, config: config}
+}
+
+// Dir returns the backup directory
+func (m *Manager) Dir() string {
+	return m.config.Dir
+}
+
+// Status returns outcome of the most recent backup attempt
+func (m *Manager) Status() Status {
+	m.statusMu.Lock()
+	defer m.statusMu.Unlock()
+
+	return m.status
+}
+
+// Create writes a verified snapshot of the live database into the backup directory
+// The snapshot is written to a temporary file first, so the directory never holds a partial copy
+func (m *Manager) Create(ctx context.Context) (*Info, error) {
+	info, err := m.create(ctx)
+
+	m.statusMu.Lock()
+	m.status.LastAt = time.Now().UTC().Format(time.RFC3339)
+	if err != nil {
+		m.status.LastStatus = "failed"
+		m.status.LastName = ""
+		m.status.LastError = err.Error()
+	} else {
+		m.status.LastStatus = "ok"
+		m.status.LastName = info.Name
+		m.status.LastError = ""
+	}
+	m.statusMu.Unlock()
+
+	return info, err
+}
+
+func (m *Manager) create(ctx context.Context) (*Info, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := os.MkdirAll(m.config.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("create backup directory: %w", err)
+	}
+
+	now := time.Now().UTC()
+	name := "scheduler_" + now.Format(nameLayout) + ".db"
+	path := filepath.Join(m.config.Dir, name)
+	// Snapshots taken within the same second get a sequence suffix
+	for i := 2; fileExists(path); i++ {
+		name = fmt.Sprintf("scheduler_%s_%d.db", now.Format(nameLayout), i)
+		path = filepath.Join(m.config.Dir, name)
+	}
+
+	tmp := path + ".tmp"
+	os.Remove(tmp)
+	if err := m.storage.Backup(ctx, tmp); err != nil {
+		os.Remove(tmp)
+		return nil, err
+	}
+	if err := db.VerifySnapshot(ctx, tmp); err != nil {
+		os.Remove(tmp)
+		log.Printf("ERROR: Fresh snapshot failed verification: %v", err)
+		return nil, fmt.Errorf("snapshot verification: %w", err)
+	}
+
+	sum, err := checksum(tmp)
+	if err != nil {
+		os.Remove(tmp)
+		return nil, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return nil, err
+	}
+	manifest := fmt.Sprintf("%s  %s\n", sum, name)
+	if err := os.WriteFile(path+manifestExt, []byte(manifest), 0644); err != nil {
+		return nil, fmt.Errorf("write checksum manifest: %w", err)
+	}
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("INFO: Backup created: %s (%d bytes, sha256 %s)", path, stat.Size(), sum)
+	return &Info{Name: name, Size: stat.Size(), SHA256: sum, CreatedAt: now.Format(time.RFC3339)}, nil
+}
+
+// List returns snapshots in the backup directory, newest first
+func (m *Manager) List() ([]Info, error) {
+	entries, err := os.ReadDir(m.config.Dir)
+	if os.IsNotExist(err) {
+		return []Info{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	backups := []Info{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".db") {
+			continue
+		}
+		stat, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		sum, _ := m.manifest(entry.Name())
+		backups = append(backups, Info{
+			Name:      entry.Name(),
+			Size:      stat.Size(),
+			SHA256:    sum,
+			CreatedAt: stat.ModTime().UTC().Format(time.RFC3339),
+		})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		if backups[i].CreatedAt != backups[j].CreatedAt {
+			return backups[i].CreatedAt > backups[j].CreatedAt
+		}
+		return backups[i].Name > backups[j].Name
+	})
+	return backups, nil
+}
+
+// Prune applies count- and age-based retention to snapshots made by the server
+// The newest snapshot is always kept. Returns number of removed snapshots
+func (m *Manager) Prune() (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	backups, err := m.List()
+	if err != nil {
+		return 0, err
+	}
+
+	cutoff := ""
+	if m.config.MaxAge > 0 {
+		cutoff = time.Now().Add(-m.config.MaxAge).UTC().Format(time.RFC3339)
+	}
+
+	removed, kept := 0, 0
+	for _, b := range backups {
+		if !strings.HasPrefix(b.Name, "scheduler_") {
+			continue
+		}
+		kept++
+		if kept == 1 {
+			continue
+		}
+		tooMany := m.config.Keep > 0 && kept > m.config.Keep
+		tooOld := cutoff != "" && b.CreatedAt < cutoff
+		if !tooMany && !tooOld {
+			continue
+		}
+
+		path := filepath.Join(m.config.Dir, b.Name)
+		if err := os.Remove(path); err != nil {
+			return removed, err
+		}
+		os.Remove(path + manifestExt)
+		removed++
+		log.Printf("INFO: Backup removed by retention policy: %s", b.Name)
+	}
+	return removed, nil
+}
+
+// Schedule takes snapshots every configured interval and applies retention until ctx is done
+// The first snapshot is due one interval after the newest existing one
+func (m *Manager) Schedule(ctx context.Context) {
+	interval := m.config.Interval
+	wait := interval
+
+	if backups, err := m.List(); err == nil && len(backups) > 0 {
+		if last, err := time.Parse(time.RFC3339, backups[0].CreatedAt); err == nil {
+			wait = max(time.Until(last.Add(interval)), 0)
+		}
+
+		// Report the newest snapshot left by a previous run until a new one is made
+		m.statusMu.Lock()
+		if m.status.LastAt == "" {
+			m.status = Status{LastAt: backups[0].CreatedAt, LastStatus: "ok", LastName: backups[0].Name}
+		}
+		m.statusMu.Unlock()
+	}
+
+	log.Printf("INFO: Automatic backups every %s into %s", interval, m.config.Dir)
+	for {
+		m.setNext(time.Now().Add(wait))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+
+		if _, err := m.Create(ctx); err != nil {
+			log.Printf("ERROR: Automatic backup failed: %v", err)
+		} else if _, err := m.Prune(); err != nil {
+			log.Printf("ERROR: Backup retention failed: %v", err)
+		}
+		wait = interval
+	}
+}
+
+// setNext records time of the next scheduled backup
+func (m *Manager) setNext(at time.Time) {
+	m.statusMu.Lock()
+	defer m.statusMu.Unlock()
+
+	m.status.NextAt = at.UTC().Format(time.RFC3339)
+}
+
+// Restore replaces the live database with the named snapshot from the backup directory
+// The snapshot checksum is checked against its manifest when one exists
+func (m *Manager) Restore(ctx context.Context, name string) error {
+	if name == "" || name != filepath.Base(name) || !strings.HasSuffix(name, ".db") {
+		return &db.ValidationError{Field: "name", Message: "invalid backup name"}
+	}
+
+	path := filepath.Join(m.config.Dir, name)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return fmt.Errorf("backup %s %w", name, db.ErrNotFound)
+	}
+
+	return m.RestoreFile(ctx, path)
+}
+
+// RestoreFile replaces the live database with the snapshot at path
+func (m *Manager) RestoreFile(ctx context.Context, path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := verifyChecksum(path); err != nil {
+		log.Printf("WARN: Snapshot %s rejected: %v", path, err)
+		return err
+	}
+
+	return m.storage.Restore(ctx, path)
+}
+
+// manifest returns checksum recorded for the named snapshot
+func (m *Manager) manifest(name string) (string, error) {
+	return readManifest(filepath.Join(m.config.Dir, name) + manifestExt)
+}
+
+// readManifest reads checksum from a file in sha256sum format
+func readManifest(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum, _, _ := strings.Cut(strings.TrimSpace(string(data)), " ")
+	return sum, nil
+}
+
+// verifyChecksum compares snapshot content with its manifest
+// Snapshots without a manifest, e.g. copied by hand, are accepted as is
+func verifyChecksum(path string) error {
+	want, err := readManifest(path + manifestExt)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	got, err := checksum(path)
+	if err != nil {
+		return err
+	}
+	if got != want {
+		return &db.ValidationError{Field: "snapshot", Message: "checksum does not match manifest"}
+	}
+	return nil
+}
+
+// fileExists reports whether path exists
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// checksum returns hex encoded SHA-256 of the file
+func checksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,229 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"strconv"
+	"time"
+	models "todo/pkg/models"
+)
+
+// attachmentColumns lists columns read by scanAttachment
+const attachmentColumns = `id, task_id, name, content_type, size, sha256, created_at`
+
+// scanAttachment reads attachment row selected with attachmentColumns
+func scanAttachment(row rowScanner) (*models.Attachment, error) {
+	var a models.Attachment
+	err := row.Scan(&a.ID, &a.TaskID, &a.Name, &a.ContentType, &a.Size, &a.SHA256, &a.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// AddAttachment records metadata of a file attached to an active task
+// Returns attachment ID or error
+func (s *Storage) AddAttachment(ctx context.Context, a *models.Attachment) (int64, error) {
+	log.Printf("DEBUG: Adding attachment to task %s: %s (%d bytes)", a.TaskID, a.Name, a.Size)
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("ERROR: Failed to begin transaction in AddAttachment: %v", err)
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := getTaskWith(ctx, tx, a.TaskID, models.PermissionEdit); err != nil {
+		return 0, err
+	}
+
+	a.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+	result, err := tx.ExecContext(ctx, `
+        INSERT INTO attachments (task_id, name, content_type, size, sha256, created_at)
+        VALUES (:task_id, :name, :content_type, :size, :sha256, :created_at)
+    `,
+		sql.Named("task_id", a.TaskID),
+		sql.Named("name", a.Name),
+		sql.Named("content_type", a.ContentType),
+		sql.Named("size", a.Size),
+		sql.Named("sha256", a.SHA256),
+		sql.Named("created_at", a.CreatedAt))
+	if err != nil {
+		log.Printf("ERROR: Database error in AddAttachment: %v", err)
+		return 0, mapError(err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		log.Printf("ERROR: Failed to get last insert ID: %v", err)
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("ERROR: Failed to commit AddAttachment: %v", err)
+		return 0, err
+	}
+
+	a.ID = strconv.FormatInt(id, 10)
+	log.Printf("INFO: Attachment added, ID: %d, task: %s", id, a.TaskID)
+	return id, nil
+}
+
+// GetAttachments retrieves attachments of an active task, oldest first
+func (s *Storage) GetAttachments(ctx context.Context, taskID string) ([]*models.Attachment, error) {
+	log.Printf("DEBUG: Getting attachments of task %s", taskID)
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	if _, err := getTask(ctx, s.db, taskID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+        SELECT `+attachmentColumns+`
+        FROM attachments
+        WHERE task_id = :task_id
+        ORDER BY id
+    `, sql.Named("task_id", taskID))
+	if err != nil {
+		log.Printf("ERROR: Database error in GetAttachments: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	attachments := make([]*models.Attachment, 0)
+	for rows.Next() {
+		a, err := scanAttachment(rows)
+		if err != nil {
+			log.Printf("ERROR: Failed to scan attachment row: %v", err)
+			return nil, err
+		}
+		attachments = append(attachments, a)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Printf("ERROR: Row iteration error in GetAttachments: %v", err)
+		return nil, err
+	}
+	return attachments, nil
+}
+
+// GetAttachment retrieves metadata of an attachment of a task visible to the context user by ID
+func (s *Storage) GetAttachment(ctx context.Context, id string) (*models.Attachment, error) {
+	log.Printf("DEBUG: Getting attachment by ID: %s", id)
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	a, err := scanAttachment(s.db.QueryRowContext(ctx, `
+        SELECT `+attachmentColumns+`
+        FROM attachments
+        WHERE id = :id AND task_id IN (SELECT id FROM scheduler WHERE `+visibleTasks+`)
+    `, sql.Named("id", id), sql.Named("owner_id", ownerID(ctx))))
+	if err == sql.ErrNoRows {
+		log.Printf("WARN: Attachment not found, ID: %s", id)
+		return nil, notFound("attachment", id)
+	}
+	if err != nil {
+		log.Printf("ERROR: Database error in GetAttachment for ID %s: %v", id, err)
+		return nil, err
+	}
+	return a, nil
+}
+
+// DeleteAttachment removes attachment metadata
+// Returns the deleted attachment, its file is removed by the caller once unreferenced
+func (s *Storage) DeleteAttachment(ctx context.Context, id string) (*models.Attachment, error) {
+	log.Printf("DEBUG: Deleting attachment, ID: %s", id)
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	a, err := scanAttachment(s.db.QueryRowContext(ctx, `
+        DELETE FROM attachments
+        WHERE id = :id AND task_id IN (SELECT id FROM scheduler WHERE `+editableTasks+`)
+        RETURNING `+attachmentColumns,
+		sql.Named("id", id),
+		sql.Named("owner_id", ownerID(ctx))))
+	if err == sql.ErrNoRows {
+		log.Printf("WARN: Attachment not found for deletion, ID: %s", id)
+		return nil, notFound("attachment", id)
+	}
+	if err != nil {
+		log.Printf("ERROR: Database error in DeleteAttachment for ID %s: %v", id, err)
+		return nil, err
+	}
+
+	log.Printf("INFO: Attachment deleted, ID: %s, task: %s", id, a.TaskID)
+	return a, nil
+}
+
+// AttachmentsSize returns total size of files referenced by attachments of existing tasks in bytes
+// Files are stored once per checksum, so a file attached several times is counted once
+func (s *Storage) AttachmentsSize(ctx context.Context) (int64, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	var size int64
+	err := s.db.QueryRowContext(ctx, `
+        SELECT ifnull(sum(size), 0) FROM (
+            SELECT max(a.size) AS size
+            FROM attachments a JOIN scheduler s ON s.id = a.task_id
+            GROUP BY a.sha256
+        )
+    `).Scan(&size)
+	if err != nil {
+		log.Printf("ERROR: Database error in AttachmentsSize: %v", err)
+	}
+	return size, err
+}
+
+// AttachmentStored reports whether an attachment of an existing task refers to the file with the checksum
+func (s *Storage) AttachmentStored(ctx context.Context, hash string) (bool, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	var stored bool
+	err := s.db.QueryRowContext(ctx, `
+        SELECT EXISTS (
+            SELECT 1 FROM attachments a JOIN scheduler s ON s.id = a.task_id
+            WHERE a.sha256 = :sha256
+        )
+    `, sql.Named("sha256", hash)).Scan(&stored)
+	if err != nil {
+		log.Printf("ERROR: Database error in AttachmentStored: %v", err)
+	}
+	return stored, err
+}
+
+// AttachmentHashes returns checksums of files referenced by attachments of existing tasks
+func (s *Storage) AttachmentHashes(ctx context.Context) (map[string]bool, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+        SELECT DISTINCT a.sha256
+        FROM attachments a JOIN scheduler s ON s.id = a.task_id
+    `)
+	if err != nil {
+		log.Printf("ERROR: Database error in AttachmentHashes: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	hashes := make(map[string]bool)
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			log.Printf("ERROR: Failed to scan attachment hash: %v", err)
+			return nil, err
+		}
+		hashes[hash] = true
+	}
+	return hashes, rows.Err()
+}
@@ -0,0 +1,149 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/url"
+	"path/filepath"
+
+	"modernc.org/sqlite"
+)
+
+// Backup writes consistent snapshot of the live database to dest using VACUUM INTO
+// dest must not exist. The query timeout is not applied, snapshots may take long
+func (s *Storage) Backup(ctx context.Context, dest string) error {
+	log.Printf("DEBUG: Writing database snapshot to %s", dest)
+
+	if _, err := s.db.ExecContext(ctx, `VACUUM INTO :dest`, sql.Named("dest", dest)); err != nil {
+		log.Printf("ERROR: Failed to write database snapshot %s: %v", dest, err)
+		return err
+	}
+
+	log.Printf("INFO: Database snapshot written: %s", dest)
+	return nil
+}
+
+// VerifySnapshot checks that file is an intact scheduler database
+// that this version of the application is able to use
+func VerifySnapshot(ctx context.Context, path string) error {
+	log.Printf("DEBUG: Verifying database snapshot %s", path)
+
+	uri, err := snapshotURI(path, "ro")
+	if err != nil {
+		return err
+	}
+	conn, err := sql.Open("sqlite", uri)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var result string
+	if err := conn.QueryRowContext(ctx, `PRAGMA integrity_check`).Scan(&result); err != nil {
+		return &ValidationError{Field: "snapshot", Message: "not a database: " + err.Error()}
+	}
+	if result != "ok" {
+		return &ValidationError{Field: "snapshot", Message: "integrity check failed: " + result}
+	}
+
+	var tables int
+	err = conn.QueryRowContext(ctx, `SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = 'scheduler'`).Scan(&tables)
+	if err != nil {
+		return err
+	}
+	if tables == 0 {
+		return &ValidationError{Field: "snapshot", Message: "scheduler table is missing"}
+	}
+
+	var version int
+	if err := conn.QueryRowContext(ctx, `PRAGMA user_version`).Scan(&version); err != nil {
+		return err
+	}
+	latest, err := latestMigration()
+	if err != nil {
+		return err
+	}
+	if version > latest {
+		return &ValidationError{Field: "snapshot", Message: fmt.Sprintf("schema version %d is newer than supported %d", version, latest)}
+	}
+
+	log.Printf("DEBUG: Database snapshot %s is valid, schema version: %d", path, version)
+	return nil
+}
+
+// Restore replaces content of the live database with the snapshot at src
+// The snapshot is verified first and copied with the SQLite backup API in a single step,
+// so other connections see either the old or the new database
+func (s *Storage) Restore(ctx context.Context, src string) error {
+	log.Printf("DEBUG: Restoring database from snapshot %s", src)
+
+	if err := VerifySnapshot(ctx, src); err != nil {
+		log.Printf("WARN: Snapshot %s rejected: %v", src, err)
+		return err
+	}
+
+	uri, err := snapshotURI(src, "ro")
+	if err != nil {
+		return err
+	}
+
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	err = conn.Raw(func(driverConn any) error {
+		restorer, ok := driverConn.(interface {
+			NewRestore(string) (*sqlite.Backup, error)
+		})
+		if !ok {
+			return fmt.Errorf("database driver does not support restore")
+		}
+
+		restore, err := restorer.NewRestore(uri)
+		if err != nil {
+			return err
+		}
+		if _, err := restore.Step(-1); err != nil {
+			restore.Finish()
+			return err
+		}
+		return restore.Finish()
+	})
+	if err != nil {
+		log.Printf("ERROR: Failed to restore database from %s: %v", src, err)
+		return err
+	}
+
+	// Snapshot may come from an older version of the application
+	if err := s.migrate(); err != nil {
+		log.Printf("ERROR: Failed to migrate restored database: %v", err)
+		return err
+	}
+
+	log.Printf("INFO: Database restored from snapshot %s", src)
+	return nil
+}
+
+// snapshotURI builds SQLite URI for the file opened with the given mode
+func snapshotURI(path, mode string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(abs), RawQuery: "mode=" + mode}
+	return u.String(), nil
+}
+
+// latestMigration returns schema version produced by all embedded migrations
+func latestMigration() (int, error) {
+	entries, err := fs.ReadDir(migrations, "migrations")
+	if err != nil {
+		return 0, err
+	}
+	return len(entries), nil
+}
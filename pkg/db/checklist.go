@@ -0,0 +1,320 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"strconv"
+	"strings"
+	models "todo/pkg/models"
+)
+
+// maxChecklistTitle - longest accepted checklist item title
+const maxChecklistTitle = 255
+
+// progressColumns selects done and total checklist item counts of the scheduler row
+const progressColumns = `(
+            SELECT ifnull(sum(done), 0) FROM checklist_items WHERE task_id = scheduler.id
+        ), (
+            SELECT count(*) FROM checklist_items WHERE task_id = scheduler.id
+        )`
+
+// validateChecklistItem trims item title and checks its length
+func validateChecklistItem(item *models.ChecklistItem) error {
+	item.Title = strings.TrimSpace(item.Title)
+	switch {
+	case item.Title == "":
+		return &ValidationError{Field: "title", Message: "the title is empty"}
+	case len([]rune(item.Title)) > maxChecklistTitle:
+		return &ValidationError{Field: "title", Message: "the title is too long"}
+	}
+	return nil
+}
+
+// GetChecklist retrieves checklist items of an active task ordered by position
+func (s *Storage) GetChecklist(ctx context.Context, taskID string) ([]*models.ChecklistItem, error) {
+	log.Printf("DEBUG: Getting checklist of task %s", taskID)
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	if _, err := getTask(ctx, s.db, taskID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+        SELECT id, task_id, title, done, position
+        FROM checklist_items
+        WHERE task_id = :task_id
+        ORDER BY position, id
+    `, sql.Named("task_id", taskID))
+	if err != nil {
+		log.Printf("ERROR: Database error in GetChecklist: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]*models.ChecklistItem, 0)
+	for rows.Next() {
+		item := &models.ChecklistItem{}
+		if err := rows.Scan(&item.ID, &item.TaskID, &item.Title, &item.Done, &item.Position); err != nil {
+			log.Printf("ERROR: Failed to scan checklist item row: %v", err)
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Printf("ERROR: Row iteration error in GetChecklist: %v", err)
+		return nil, err
+	}
+	log.Printf("DEBUG: Retrieved %d checklist items of task %s", len(items), taskID)
+	return items, nil
+}
+
+// AddChecklistItem appends item to the checklist of an active task
+// Returns item ID or error
+func (s *Storage) AddChecklistItem(ctx context.Context, item *models.ChecklistItem) (int64, error) {
+	log.Printf("DEBUG: Adding checklist item to task %s: %s", item.TaskID, item.Title)
+
+	if err := validateChecklistItem(item); err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("ERROR: Failed to begin transaction in AddChecklistItem: %v", err)
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := getTaskWith(ctx, tx, item.TaskID, models.PermissionEdit); err != nil {
+		return 0, err
+	}
+
+	var id int64
+	err = tx.QueryRowContext(ctx, `
+        INSERT INTO checklist_items (task_id, position, title, done)
+        SELECT :task_id, ifnull(max(position), 0) + 1, :title, :done
+        FROM checklist_items WHERE task_id = :task_id
+        RETURNING id, position
+    `,
+		sql.Named("task_id", item.TaskID),
+		sql.Named("title", item.Title),
+		sql.Named("done", item.Done)).Scan(&id, &item.Position)
+	if err != nil {
+		log.Printf("ERROR: Database error in AddChecklistItem: %v", err)
+		return 0, mapError(err)
+	}
+	item.ID = strconv.FormatInt(id, 10)
+
+	if err := touchTask(ctx, tx, item.TaskID); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("ERROR: Failed to commit AddChecklistItem: %v", err)
+		return 0, err
+	}
+
+	log.Printf("INFO: Checklist item added, ID: %s, task: %s", item.ID, item.TaskID)
+	return id, nil
+}
+
+// UpdateChecklistItem changes title and done state of the item
+// item.TaskID is set to the task of the item
+func (s *Storage) UpdateChecklistItem(ctx context.Context, item *models.ChecklistItem) error {
+	log.Printf("DEBUG: Updating checklist item, ID: %s", item.ID)
+
+	if err := validateChecklistItem(item); err != nil {
+		return err
+	}
+
+	return s.changeChecklistItem(ctx, item.ID, func(ctx context.Context, tx *sql.Tx) error {
+		return tx.QueryRowContext(ctx, `
+            UPDATE checklist_items
+            SET title = :title,
+                done = :done
+            WHERE id = :id
+            RETURNING task_id, position
+        `,
+			sql.Named("id", item.ID),
+			sql.Named("title", item.Title),
+			sql.Named("done", item.Done)).Scan(&item.TaskID, &item.Position)
+	})
+}
+
+// ToggleChecklistItem flips done state of the item
+// Returns the changed item
+func (s *Storage) ToggleChecklistItem(ctx context.Context, id string) (*models.ChecklistItem, error) {
+	log.Printf("DEBUG: Toggling checklist item, ID: %s", id)
+
+	item := &models.ChecklistItem{ID: id}
+	err := s.changeChecklistItem(ctx, id, func(ctx context.Context, tx *sql.Tx) error {
+		return tx.QueryRowContext(ctx, `
+            UPDATE checklist_items
+            SET done = NOT done
+            WHERE id = :id
+            RETURNING task_id, title, done, position
+        `, sql.Named("id", id)).Scan(&item.TaskID, &item.Title, &item.Done, &item.Position)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// DeleteChecklistItem removes the item from its checklist
+func (s *Storage) DeleteChecklistItem(ctx context.Context, id string) error {
+	log.Printf("DEBUG: Deleting checklist item, ID: %s", id)
+
+	return s.changeChecklistItem(ctx, id, func(ctx context.Context, tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `DELETE FROM checklist_items WHERE id = :id`, sql.Named("id", id))
+		return err
+	})
+}
+
+// changeChecklistItem runs change of an item of an active task in a transaction
+// and bumps version of the task
+func (s *Storage) changeChecklistItem(ctx context.Context, id string, change func(ctx context.Context, tx *sql.Tx) error) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("ERROR: Failed to begin checklist transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	var taskID string
+	err = tx.QueryRowContext(ctx, `
+        SELECT c.task_id
+        FROM checklist_items c JOIN scheduler s ON s.id = c.task_id
+        WHERE c.id = :id AND s.deleted_at = '' AND s.id IN (SELECT id FROM scheduler WHERE `+editableTasks+`)
+    `, sql.Named("id", id), sql.Named("owner_id", ownerID(ctx))).Scan(&taskID)
+	if err == sql.ErrNoRows {
+		log.Printf("WARN: Checklist item not found, ID: %s", id)
+		return notFound("checklist item", id)
+	}
+	if err != nil {
+		log.Printf("ERROR: Database error reading checklist item %s: %v", id, err)
+		return err
+	}
+
+	if err := change(ctx, tx); err != nil {
+		log.Printf("ERROR: Database error changing checklist item %s: %v", id, err)
+		return mapError(err)
+	}
+	if err := touchTask(ctx, tx, taskID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("ERROR: Failed to commit checklist change: %v", err)
+		return err
+	}
+
+	log.Printf("INFO: Checklist item changed, ID: %s, task: %s", id, taskID)
+	return nil
+}
+
+// ReorderChecklist sets order of checklist items of the task
+// ids must list every item of the checklist exactly once
+func (s *Storage) ReorderChecklist(ctx context.Context, taskID string, ids []string) error {
+	log.Printf("DEBUG: Reordering checklist of task %s: %v", taskID, ids)
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("ERROR: Failed to begin transaction in ReorderChecklist: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := getTaskWith(ctx, tx, taskID, models.PermissionEdit); err != nil {
+		return err
+	}
+
+	var total int
+	err = tx.QueryRowContext(ctx, `SELECT count(*) FROM checklist_items WHERE task_id = :task_id`,
+		sql.Named("task_id", taskID)).Scan(&total)
+	if err != nil {
+		log.Printf("ERROR: Database error in ReorderChecklist: %v", err)
+		return err
+	}
+
+	invalid := &ValidationError{Field: "items", Message: "items must list every checklist item of the task once"}
+	if len(ids) != total {
+		return invalid
+	}
+
+	seen := make(map[string]bool, len(ids))
+	for i, id := range ids {
+		if seen[id] {
+			return invalid
+		}
+		seen[id] = true
+
+		result, err := tx.ExecContext(ctx, `
+            UPDATE checklist_items
+            SET position = :position
+            WHERE id = :id AND task_id = :task_id
+        `,
+			sql.Named("position", i+1),
+			sql.Named("id", id),
+			sql.Named("task_id", taskID))
+		if err != nil {
+			log.Printf("ERROR: Failed to move checklist item %s: %v", id, err)
+			return err
+		}
+		if count, err := result.RowsAffected(); err != nil || count == 0 {
+			return invalid
+		}
+	}
+
+	if err := touchTask(ctx, tx, taskID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("ERROR: Failed to commit ReorderChecklist: %v", err)
+		return err
+	}
+
+	log.Printf("INFO: Checklist of task %s reordered", taskID)
+	return nil
+}
+
+// resetChecklist marks all checklist items of the task as not done
+// task.Progress is updated accordingly
+func resetChecklist(ctx context.Context, q querier, task *models.Task) error {
+	if task.Progress == nil {
+		return nil
+	}
+
+	_, err := q.ExecContext(ctx, `UPDATE checklist_items SET done = 0 WHERE task_id = :task_id`,
+		sql.Named("task_id", task.ID))
+	if err != nil {
+		log.Printf("ERROR: Failed to reset checklist of task %s: %v", task.ID, err)
+		return err
+	}
+
+	task.Progress = &models.Progress{Total: task.Progress.Total}
+	return nil
+}
+
+// touchTask bumps version of the task, its checklist has changed
+func touchTask(ctx context.Context, q querier, taskID string) error {
+	_, err := q.ExecContext(ctx, `UPDATE scheduler SET version = version + 1 WHERE id = :id`,
+		sql.Named("id", taskID))
+	if err != nil {
+		log.Printf("ERROR: Failed to update version of task %s: %v", taskID, err)
+	}
+	return err
+}
@@ -0,0 +1,212 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+	models "todo/pkg/models"
+)
+
+// NextDateFunc calculates next date of a recurring task after now
+type NextDateFunc func(now time.Time, date, repeat string) (string, error)
+
+// dateLayout - format of task dates
+const dateLayout = "20060102"
+
+// ShiftStart moves start date by as many days as the due date moved from due to next
+// Empty start stays empty
+func ShiftStart(start, due, next string) (string, error) {
+	if start == "" || due == next {
+		return start, nil
+	}
+
+	s, err := time.Parse(dateLayout, start)
+	if err != nil {
+		return "", &ValidationError{Field: "start", Message: "invalid start date format"}
+	}
+	from, err := time.Parse(dateLayout, due)
+	if err != nil {
+		return "", &ValidationError{Field: "date", Message: "invalid date format"}
+	}
+	to, err := time.Parse(dateLayout, next)
+	if err != nil {
+		return "", &ValidationError{Field: "date", Message: "invalid date format"}
+	}
+
+	days := int(to.Sub(from).Hours() / 24)
+	return s.AddDate(0, 0, days).Format(dateLayout), nil
+}
+
+// CompleteTask marks task as done in a single transaction
+// Recurring task is moved to its next date with its checklist reset and its start date shifted by the same number of days,
+// one-time task is marked completed and leaves the task lists, see ReopenTask,
+// tasks blocked by the task are released, users the task is shared with may complete it too,
+// completion and "done" revision are recorded in the same transaction.
+// The transaction takes the write lock on begin, so concurrent calls are serialized
+// id - task identifier
+// note - optional completion note
+// actor - who completed the task, stored in the revision
+// next - calculates next date of a recurring task
+// Returns task state before and after completion
+func (s *Storage) CompleteTask(ctx context.Context, id, note, actor string, next NextDateFunc) (*models.Task, *models.Task, error) {
+	log.Printf("DEBUG: Completing task, ID: %s", id)
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("ERROR: Failed to begin transaction in CompleteTask: %v", err)
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	before, after, err := completeTask(ctx, tx, id, note, actor, next)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("ERROR: Failed to commit CompleteTask for ID %s: %v", id, err)
+		return nil, nil, err
+	}
+
+	log.Printf("INFO: Task completed, ID: %s, next date: %s, completed at: %s", id, after.Date, after.CompletedAt)
+	return before, after, nil
+}
+
+// completeTask advances or retires task and records completion using q, which must be a transaction
+// A shared task is a single row, so a recurring one moves to its next date for everyone it is shared with
+func completeTask(ctx context.Context, q querier, id, note, actor string, next NextDateFunc) (*models.Task, *models.Task, error) {
+	before, err := getTaskWith(ctx, q, id, models.PermissionEdit)
+	if err != nil {
+		return nil, nil, err
+	}
+	if before.CompletedAt != "" {
+		log.Printf("WARN: Task %s is already done", id)
+		return nil, nil, fmt.Errorf("%w: task %s is already done", ErrConflict, id)
+	}
+
+	after := *before
+	if before.Repeat == "" {
+		// One-time task - keep it as completed
+		after.CompletedAt = time.Now().UTC().Format(time.RFC3339)
+		err = q.QueryRowContext(ctx, `
+            UPDATE scheduler
+            SET completed_at = :completed_at,
+                version = version + 1
+            WHERE id = :id
+            RETURNING version
+        `,
+			sql.Named("completed_at", after.CompletedAt),
+			sql.Named("id", id)).Scan(&after.Version)
+	} else {
+		// Recurring task - move it to the next date, a snoozed occurrence keeps the series in place
+		from := before.Date
+		if before.SnoozedFrom != "" {
+			from = before.SnoozedFrom
+		}
+		after.SnoozedFrom = ""
+		after.Date, err = next(time.Now(), from, before.Repeat)
+		if err != nil {
+			log.Printf("WARN: Next date calculation failed for recurring task %s: %v", id, err)
+			return nil, nil, &ValidationError{Field: "repeat", Message: err.Error()}
+		}
+		after.Start, err = ShiftStart(before.Start, before.Date, after.Date)
+		if err != nil {
+			return nil, nil, err
+		}
+		err = q.QueryRowContext(ctx, `
+            UPDATE scheduler
+            SET date = :date,
+                start = :start,
+                snoozed_from = '',
+                version = version + 1
+            WHERE id = :id
+            RETURNING version
+        `,
+			sql.Named("date", after.Date),
+			sql.Named("start", after.Start),
+			sql.Named("id", id)).Scan(&after.Version)
+		if err == nil {
+			err = resetChecklist(ctx, q, &after)
+		}
+	}
+	if err == nil {
+		err = releaseDependents(ctx, q, id)
+	}
+	if err != nil {
+		log.Printf("ERROR: Database error in CompleteTask for ID %s: %v", id, err)
+		return nil, nil, mapError(err)
+	}
+
+	_, err = addCompletion(ctx, q, &models.Completion{
+		TaskID: before.ID,
+		Title:  before.Title,
+		Date:   before.Date,
+		Note:   note,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := addRevision(ctx, q, before.ID, models.ActionDone, actor, before, &after); err != nil {
+		return nil, nil, err
+	}
+
+	return before, &after, nil
+}
+
+// ReopenTask returns completed one-time task to the task lists in a single transaction
+// The completion stays in the log, "reopen" revision is recorded in the same transaction
+// id - task identifier
+// actor - who reopened the task, stored in the revision
+// Returns task state before and after reopening
+func (s *Storage) ReopenTask(ctx context.Context, id, actor string) (*models.Task, *models.Task, error) {
+	log.Printf("DEBUG: Reopening task, ID: %s", id)
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("ERROR: Failed to begin transaction in ReopenTask: %v", err)
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	before, err := getTaskWith(ctx, tx, id, models.PermissionEdit)
+	if err != nil {
+		return nil, nil, err
+	}
+	if before.CompletedAt == "" {
+		log.Printf("WARN: Task %s is not done, nothing to reopen", id)
+		return nil, nil, fmt.Errorf("%w: task %s is not done", ErrConflict, id)
+	}
+
+	after := *before
+	after.CompletedAt = ""
+	err = tx.QueryRowContext(ctx, `
+        UPDATE scheduler
+        SET completed_at = '',
+            version = version + 1
+        WHERE id = :id
+        RETURNING version
+    `, sql.Named("id", id)).Scan(&after.Version)
+	if err != nil {
+		log.Printf("ERROR: Database error in ReopenTask for ID %s: %v", id, err)
+		return nil, nil, err
+	}
+	if _, err := addRevision(ctx, tx, id, models.ActionReopen, actor, before, &after); err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("ERROR: Failed to commit ReopenTask for ID %s: %v", id, err)
+		return nil, nil, err
+	}
+
+	log.Printf("INFO: Task reopened, ID: %s", id)
+	return before, &after, nil
+}
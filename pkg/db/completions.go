@@ -0,0 +1,116 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"strings"
+	"time"
+	models "todo/pkg/models"
+)
+
+// CompletionsResp represents response structure for completion log
+type CompletionsResp struct {
+	Completions []*models.Completion `json:"completions"`
+}
+
+// CompletionFilter describes which completions GetCompletions returns
+// Empty fields are not used for filtering
+type CompletionFilter struct {
+	TaskID string
+	From   string // first scheduled date in YYYYMMDD format, inclusive
+	To     string // last scheduled date in YYYYMMDD format, inclusive
+	Limit  int
+}
+
+// addCompletion records that task was marked as done using q, which must be the transaction of the completion
+// Sets CompletedAt when it is empty
+// The record belongs to the owner of the task, also when a user it is shared with completes it
+func addCompletion(ctx context.Context, q querier, c *models.Completion) (int64, error) {
+	if c.CompletedAt == "" {
+		c.CompletedAt = time.Now().UTC().Format(time.RFC3339)
+	}
+
+	result, err := q.ExecContext(ctx, `
+        INSERT INTO completions (task_id, title, date, completed_at, note, owner_id)
+        VALUES (:task_id, :title, :date, :completed_at, :note,
+                ifnull((SELECT owner_id FROM scheduler WHERE id = :task_id), :owner_id))
+    `,
+		sql.Named("task_id", c.TaskID),
+		sql.Named("title", c.Title),
+		sql.Named("date", c.Date),
+		sql.Named("completed_at", c.CompletedAt),
+		sql.Named("note", c.Note),
+		sql.Named("owner_id", ownerID(ctx)))
+
+	if err != nil {
+		log.Printf("ERROR: Database error recording completion of task %s: %v", c.TaskID, err)
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		log.Printf("ERROR: Failed to get last insert ID: %v", err)
+		return 0, err
+	}
+
+	log.Printf("INFO: Completion recorded, ID: %d, task ID: %s", id, c.TaskID)
+	return id, nil
+}
+
+// GetCompletions retrieves completion log of the context user and of tasks visible to the user, most recent first
+func (s *Storage) GetCompletions(ctx context.Context, filter CompletionFilter) (CompletionsResp, error) {
+	log.Printf("DEBUG: Getting completions, task: '%s', from: '%s', to: '%s'", filter.TaskID, filter.From, filter.To)
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	where := []string{"(owner_id = :owner_id OR task_id IN (SELECT id FROM scheduler WHERE " + visibleTasks + "))"}
+	args := []any{sql.Named("limit", filter.Limit), sql.Named("owner_id", ownerID(ctx))}
+
+	if filter.TaskID != "" {
+		where = append(where, "task_id = :task_id")
+		args = append(args, sql.Named("task_id", filter.TaskID))
+	}
+	if filter.From != "" {
+		where = append(where, "date >= :from")
+		args = append(args, sql.Named("from", filter.From))
+	}
+	if filter.To != "" {
+		where = append(where, "date <= :to")
+		args = append(args, sql.Named("to", filter.To))
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+        SELECT id, task_id, title, date, completed_at, note
+        FROM completions
+        WHERE `+strings.Join(where, " AND ")+`
+        ORDER BY completed_at DESC, id DESC
+        LIMIT :limit
+    `, args...)
+	if err != nil {
+		log.Printf("ERROR: Database error in GetCompletions: %v", err)
+		return CompletionsResp{}, err
+	}
+	defer rows.Close()
+
+	var resp CompletionsResp
+	resp.Completions = make([]*models.Completion, 0)
+
+	for rows.Next() {
+		c := &models.Completion{}
+		err := rows.Scan(&c.ID, &c.TaskID, &c.Title, &c.Date, &c.CompletedAt, &c.Note)
+		if err != nil {
+			log.Printf("ERROR: Failed to scan completion row: %v", err)
+			return CompletionsResp{}, err
+		}
+		resp.Completions = append(resp.Completions, c)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Printf("ERROR: Row iteration error in GetCompletions: %v", err)
+		return CompletionsResp{}, err
+	}
+	log.Printf("DEBUG: Retrieved %d completions", len(resp.Completions))
+	return resp, nil
+}
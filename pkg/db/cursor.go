@@ -0,0 +1,69 @@
+package db
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	models "todo/pkg/models"
+)
+
+// errInvalidCursor is returned when a pagination cursor cannot be decoded
+var errInvalidCursor = &ValidationError{Field: "cursor", Message: "invalid cursor"}
+
+// Task list orders accepted in TaskFilter.Order
+const (
+	OrderByDate     = "date"     // date, then priority
+	OrderByPriority = "priority" // priority, then date
+)
+
+// taskOrders maps list order to ORDER BY clause and the matching keyset condition
+var taskOrders = map[string]struct{ by, after string }{
+	OrderByDate: {
+		by:    "date ASC, priority ASC, id ASC",
+		after: "(date, priority, id) > (:after_date, :after_priority, :after_id)",
+	},
+	OrderByPriority: {
+		by:    "priority ASC, date ASC, id ASC",
+		after: "(priority, date, id) > (:after_priority, :after_date, :after_id)",
+	},
+}
+
+// Cursor points at the last task of a page, it holds every column tasks are ordered by
+type Cursor struct {
+	Date     string `json:"d"`
+	Priority int    `json:"p,omitempty"`
+	ID       int64  `json:"i"`
+}
+
+// Encode returns opaque URL-safe representation of the cursor
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses cursor previously produced by Encode
+func DecodeCursor(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID <= 0 {
+		return nil, errInvalidCursor
+	}
+	return &c, nil
+}
+
+// cursorAfter builds cursor pointing at the given task
+func cursorAfter(task *models.Task) (Cursor, error) {
+	id, err := strconv.ParseInt(task.ID, 10, 64)
+	if err != nil {
+		return Cursor{}, err
+	}
+	priority, err := priorityLevel(task.Priority)
+	if err != nil {
+		return Cursor{}, err
+	}
+	return Cursor{Date: task.Date, Priority: priority, ID: id}, nil
+}
@@ -1,12 +1,17 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	_ "embed"
 	"fmt"
 	"log"
-
+	"net/url"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 	models "todo/pkg/models"
 
 	_ "modernc.org/sqlite"
@@ -15,28 +20,136 @@ import (
 //go:embed schema.sql
 var schema string
 
+// Config holds tunable storage settings
+type Config struct {
+	QueryTimeout time.Duration // deadline for a single storage call, zero disables it
+	JournalMode  string        // journal_mode pragma: WAL, DELETE, TRUNCATE, PERSIST, MEMORY or OFF
+	Synchronous  string        // synchronous pragma: OFF, NORMAL, FULL or EXTRA
+	BusyTimeout  time.Duration // how long a connection waits for a lock before failing with SQLITE_BUSY
+	ForeignKeys  bool          // enforce foreign key constraints
+	MaxOpenConns int           // upper bound of open connections, zero means unlimited
+	MaxIdleConns int           // upper bound of idle connections kept in the pool
+}
+
+var (
+	journalModes = []string{"WAL", "DELETE", "TRUNCATE", "PERSIST", "MEMORY", "OFF"}
+	syncLevels   = []string{"OFF", "NORMAL", "FULL", "EXTRA"}
+)
+
+// dsn builds connection string applying config pragmas to every pooled connection
+// Transactions start with BEGIN IMMEDIATE, so writers queue on busy_timeout
+// instead of failing when a read lock cannot be upgraded
+func (c Config) dsn(dbFile string) (string, error) {
+	q := url.Values{}
+
+	if c.JournalMode != "" {
+		mode := strings.ToUpper(c.JournalMode)
+		if !slices.Contains(journalModes, mode) {
+			return "", fmt.Errorf("invalid journal mode %q", c.JournalMode)
+		}
+		q.Add("_pragma", "journal_mode("+mode+")")
+	}
+	if c.Synchronous != "" {
+		level := strings.ToUpper(c.Synchronous)
+		if !slices.Contains(syncLevels, level) {
+			return "", fmt.Errorf("invalid synchronous level %q", c.Synchronous)
+		}
+		q.Add("_pragma", "synchronous("+level+")")
+	}
+	q.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", c.BusyTimeout.Milliseconds()))
+	if c.ForeignKeys {
+		q.Add("_pragma", "foreign_keys(1)")
+	} else {
+		q.Add("_pragma", "foreign_keys(0)")
+	}
+	q.Set("_txlock", "immediate")
+
+	return dbFile + "?" + q.Encode(), nil
+}
+
+// querier is implemented by both *sql.DB and *sql.Tx
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
 // Storage represents database storage layer for scheduler tasks
 type Storage struct {
-	db *sql.DB
+	db     *sql.DB
+	config Config
+}
+
+// taskColumns lists scheduler columns read by scanTask, queries selecting them bind :owner_id
+const taskColumns = `id, date, start, snoozed_from, title, comment, repeat, version, priority, completed_at, deleted_at, ifnull(project_id, ''), ` +
+	tagsColumn + `, ` + progressColumns + `, ` + blockersColumns + `, ` + sharingColumns
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanTask reads task row selected with taskColumns
+func scanTask(row rowScanner) (*models.Task, error) {
+	var t models.Task
+	var project, tags, blockers string
+	var assignee sql.NullString
+	var priority int
+	var progress models.Progress
+	err := row.Scan(&t.ID, &t.Date, &t.Start, &t.SnoozedFrom, &t.Title, &t.Comment, &t.Repeat, &t.Version, &priority, &t.CompletedAt, &t.DeletedAt, &project, &tags,
+		&progress.Done, &progress.Total, &blockers, &t.Blocked, &t.OwnerID, &t.Owner, &assignee, &t.Permission)
+	if err != nil {
+		return nil, err
+	}
+	if assignee.Valid {
+		t.Assignee = &assignee.String
+	}
+	if progress.Total > 0 {
+		t.Progress = &progress
+	}
+	t.Priority = fmt.Sprintf("P%d", priority)
+	if project != "" {
+		t.Project = &project
+	}
+	t.Tags = splitTags(tags)
+	t.BlockedBy = splitBlockers(blockers)
+	return &t, nil
+}
+
+// priorityLevel converts priority P1-P4 to the stored number 1-4
+func priorityLevel(priority string) (int, error) {
+	level, err := strconv.Atoi(strings.TrimPrefix(strings.ToUpper(priority), "P"))
+	if err != nil || level < 1 || level > 4 {
+		return 0, &ValidationError{Field: "priority", Message: "priority must be one of P1, P2, P3, P4"}
+	}
+	return level, nil
 }
 
 // TasksResp represents response structure for tasks list
 type TasksResp struct {
 	Tasks []*models.Task `json:"tasks"`
+	Next  string         `json:"next,omitempty"`
 }
 
 // NewStorage creates a new instance of Storage
-func NewStorage(dbFile string) (*Storage, error) {
+func NewStorage(dbFile string, config Config) (*Storage, error) {
 	_, err := os.Stat(dbFile)
 	install := os.IsNotExist(err)
 
-	conn, err := sql.Open("sqlite", dbFile)
+	dsn, err := config.dsn(dbFile)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := sql.Open("sqlite", dsn)
 	if err != nil {
 		log.Printf("ERROR: Failed to open database %s: %v", dbFile, err)
 		return nil, err
 	}
+	conn.SetMaxOpenConns(config.MaxOpenConns)
+	conn.SetMaxIdleConns(config.MaxIdleConns)
 
-	storage := &Storage{db: conn}
+	storage := &Storage{db: conn, config: config}
 
 	if install {
 		log.Printf("INFO: Database schema not found, creating new database")
@@ -47,6 +160,16 @@ func NewStorage(dbFile string) (*Storage, error) {
 		log.Printf("INFO: Database schema created successfully")
 	}
 
+	if err := storage.migrate(); err != nil {
+		log.Printf("ERROR: Failed to migrate database schema: %v", err)
+		return nil, err
+	}
+
+	var mode string
+	if err := storage.db.QueryRow("PRAGMA journal_mode").Scan(&mode); err == nil {
+		log.Printf("INFO: Database journal mode: %s", mode)
+	}
+
 	log.Printf("INFO: Database initialized successfully: %s", dbFile)
 	return storage, nil
 }
@@ -56,23 +179,86 @@ func (s *Storage) Close() error {
 	return s.db.Close()
 }
 
-// AddTask creates a new task in the scheduler
-// Returns task ID or error
-func (s *Storage) AddTask(task *models.Task) (int64, error) {
+// Ping checks that the database is reachable
+func (s *Storage) Ping(ctx context.Context) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	var one int
+	return s.db.QueryRowContext(ctx, `SELECT 1`).Scan(&one)
+}
+
+// withTimeout limits ctx by the configured query timeout
+// The returned cancel function must always be called
+func (s *Storage) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.config.QueryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, s.config.QueryTimeout)
+}
+
+// AddTask creates a new task in the scheduler, "create" revision is recorded in the same transaction
+// actor - who created the task, stored in the revision
+// Returns task ID or error, task.ID is set to the new ID
+func (s *Storage) AddTask(ctx context.Context, task *models.Task, actor string) (int64, error) {
 	log.Printf("DEBUG: Adding new task: %s", task.Title)
 
-	result, err := s.db.Exec(`
-		INSERT INTO scheduler (date, title, comment, repeat)
-		VALUES (:date, :title, :comment, :repeat)
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("ERROR: Failed to begin transaction in AddTask: %v", err)
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	id, err := addTask(ctx, tx, task, actor)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("ERROR: Failed to commit AddTask: %v", err)
+		return 0, err
+	}
+	return id, nil
+}
+
+// addTask inserts task with its tags and blockers and records its revision using q, which must be a transaction
+func addTask(ctx context.Context, q querier, task *models.Task, actor string) (int64, error) {
+	if err := checkProject(ctx, q, task.Project); err != nil {
+		return 0, err
+	}
+	if task.Priority == "" {
+		task.Priority = models.PriorityDefault
+	}
+	priority, err := priorityLevel(task.Priority)
+	if err != nil {
+		return 0, err
+	}
+	assignee, err := assigneeArg(ctx, q, task.Assignee)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := q.ExecContext(ctx, `
+		INSERT INTO scheduler (date, start, title, comment, repeat, priority, project_id, owner_id, assignee_id)
+		VALUES (:date, :start, :title, :comment, :repeat, :priority, :project_id, :owner_id, :assignee_id)
     `,
 		sql.Named("date", task.Date),
+		sql.Named("start", task.Start),
 		sql.Named("title", task.Title),
 		sql.Named("comment", task.Comment),
-		sql.Named("repeat", task.Repeat))
+		sql.Named("repeat", task.Repeat),
+		sql.Named("priority", priority),
+		sql.Named("project_id", projectArg(task.Project)),
+		sql.Named("owner_id", ownerID(ctx)),
+		sql.Named("assignee_id", assignee))
 
 	if err != nil {
 		log.Printf("ERROR: Database error in AddTask: %v", err)
-		return 0, err
+		return 0, mapError(err)
 	}
 
 	id, err := result.LastInsertId()
@@ -81,21 +267,144 @@ func (s *Storage) AddTask(task *models.Task) (int64, error) {
 		return 0, err
 	}
 
+	task.ID = strconv.FormatInt(id, 10)
+	if len(task.Tags) > 0 || len(task.BlockedBy) > 0 {
+		if err := setTaskTags(ctx, q, task); err != nil {
+			return 0, err
+		}
+		if err := setTaskBlockers(ctx, q, task, nil); err != nil {
+			return 0, err
+		}
+	}
+	if _, err := addRevision(ctx, q, task.ID, models.ActionCreate, actor, nil, task); err != nil {
+		return 0, err
+	}
+
 	log.Printf("INFO: Task created successfully, ID: %d, Title: %s", id, task.Title)
 	return id, nil
 }
 
-// GetTasks retrieves tasks list with pagination
-// limit - maximum number of tasks to return
-func (s *Storage) GetTasks(limit int) (TasksResp, error) {
-	log.Printf("DEBUG: Getting tasks list, limit: %d", limit)
+// TaskFilter describes which tasks GetTasks returns
+// Search and Date are mutually exclusive, Date takes precedence
+type TaskFilter struct {
+	Search   string  // substring of title or comment
+	Date     string  // exact date in YYYYMMDD format
+	Tag      string  // name of a tag the task must have, combined with other filters
+	Project  string  // ID of the project the task belongs to, combined with other filters
+	Scope    string  // ScopeAssigned or ScopeShared, empty lists own, assigned and shared tasks
+	Status   string  // StatusActive (default) or StatusDone
+	Inbox    bool    // list undated tasks only, other lists leave them out until they are scheduled
+	Today    string  // current date in YYYYMMDD format, tasks starting after it are left out unless Deferred is set
+	Deferred bool    // also list tasks which have not started yet
+	Overdue  bool    // only tasks due before Today
+	Order    string  // OrderByDate (default) or OrderByPriority
+	Limit    int     // maximum number of tasks to return
+	After    *Cursor // position of the last task of the previous page
+}
+
+// Task list scopes accepted in TaskFilter.Scope
+const (
+	ScopeAssigned = "assigned" // tasks assigned to the context user
+	ScopeShared   = "shared"   // tasks of other users shared with the context user
+)
+
+// Task statuses accepted in TaskFilter.Status
+const (
+	StatusActive = "active" // tasks still to be done
+	StatusDone   = "done"   // completed one-time tasks
+)
+
+// taskScopes maps list scope to the condition on scheduler rows
+var taskScopes = map[string]string{
+	ScopeAssigned: "assignee_id = :owner_id",
+	ScopeShared:   "id IN (SELECT task_id FROM task_shares WHERE user_id = :owner_id)",
+}
+
+// GetTasks retrieves tasks list with cursor pagination
+// Tasks are ordered by (date, priority, id) or (priority, date, id), see TaskFilter.Order
+// Next is set when more tasks follow
+func (s *Storage) GetTasks(ctx context.Context, filter TaskFilter) (TasksResp, error) {
+	log.Printf("DEBUG: Getting tasks list, search: '%s', date: '%s', limit: %d", filter.Search, filter.Date, filter.Limit)
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	where := []string{"deleted_at = ''", visibleTasks}
+	args := []any{sql.Named("limit", filter.Limit+1), sql.Named("owner_id", ownerID(ctx))}
+
+	switch filter.Status {
+	case "", StatusActive:
+		where = append(where, "completed_at = ''")
+		if !filter.Inbox {
+			where = append(where, "date != ''")
+		}
+		if !filter.Deferred {
+			where = append(where, "start <= :today")
+		}
+		if filter.Overdue {
+			where = append(where, "date < :today")
+		}
+		args = append(args, sql.Named("today", filter.Today))
+	case StatusDone:
+		// Completed tasks are listed whatever their dates are
+		where = append(where, "completed_at != ''")
+	default:
+		return TasksResp{}, &ValidationError{Field: "status", Message: "status must be active or done"}
+	}
+	if filter.Inbox {
+		where = append(where, "date = ''")
+	}
+
+	if filter.Scope != "" {
+		scope, ok := taskScopes[filter.Scope]
+		if !ok {
+			return TasksResp{}, &ValidationError{Field: "scope", Message: "scope must be assigned or shared"}
+		}
+		where = append(where, scope)
+	}
+
+	switch {
+	case filter.Date != "":
+		where = append(where, "date = :date")
+		args = append(args, sql.Named("date", filter.Date))
+	case filter.Search != "":
+		where = append(where, "(title LIKE :search OR comment LIKE :search)")
+		args = append(args, sql.Named("search", "%"+filter.Search+"%"))
+	}
+
+	if filter.Tag != "" {
+		where = append(where, tagFilter)
+		args = append(args, sql.Named("tag", tagKey(strings.TrimSpace(strings.TrimPrefix(filter.Tag, "#")))))
+	}
+
+	if filter.Project != "" {
+		where = append(where, "project_id = :project_id")
+		args = append(args, sql.Named("project_id", filter.Project))
+	}
+
+	if filter.Order == "" {
+		filter.Order = OrderByDate
+	}
+	order, ok := taskOrders[filter.Order]
+	if !ok {
+		return TasksResp{}, &ValidationError{Field: "order", Message: "order must be date or priority"}
+	}
+
+	if filter.After != nil {
+		where = append(where, order.after)
+		args = append(args,
+			sql.Named("after_date", filter.After.Date),
+			sql.Named("after_priority", filter.After.Priority),
+			sql.Named("after_id", filter.After.ID))
+	}
 
-	rows, err := s.db.Query(`
-        SELECT id, date, title, comment, repeat 
-        FROM scheduler 
-        ORDER BY date ASC 
+	rows, err := s.db.QueryContext(ctx, `
+        SELECT `+taskColumns+`
+        FROM scheduler
+        WHERE `+strings.Join(where, " AND ")+`
+        ORDER BY `+order.by+`
         LIMIT :limit
-    `, sql.Named("limit", limit))
+    `, args...)
 	if err != nil {
 		log.Printf("ERROR: Database error in GetTasks: %v", err)
 		return TasksResp{}, err
@@ -106,8 +415,7 @@ func (s *Storage) GetTasks(limit int) (TasksResp, error) {
 	resp.Tasks = make([]*models.Task, 0)
 
 	for rows.Next() {
-		t := &models.Task{}
-		err := rows.Scan(&t.ID, &t.Date, &t.Title, &t.Comment, &t.Repeat)
+		t, err := scanTask(rows)
 		if err != nil {
 			log.Printf("ERROR: Failed to scan task row: %v", err)
 			return TasksResp{}, err
@@ -119,221 +427,250 @@ func (s *Storage) GetTasks(limit int) (TasksResp, error) {
 		log.Printf("ERROR: Row iteration error in GetTasks: %v", err)
 		return TasksResp{}, err
 	}
-	log.Printf("DEBUG: Retrieved %d tasks", len(resp.Tasks))
-	return resp, nil
-}
-
-// GetTasksByTitle searches tasks by title or comment
-// limit - maximum number of tasks to return
-// search - search query string
-func (s *Storage) GetTasksByTitle(limit int, search string) (TasksResp, error) {
-	log.Printf("DEBUG: Searching tasks by title/comment: '%s', limit: %d", search, limit)
-
-	rows, err := s.db.Query(`
-        SELECT id, date, title, comment, repeat 
-		FROM scheduler
-        WHERE title LIKE :search OR comment LIKE :search
-		ORDER BY date ASC
-		LIMIT :limit
-    `,
-		sql.Named("search", "%"+search+"%"),
-		sql.Named("limit", limit))
-	if err != nil {
-		log.Printf("ERROR: Database error in GetTasksByTitle: %v", err)
-		return TasksResp{}, err
-
-	}
-	defer rows.Close()
-
-	var resp TasksResp
-	resp.Tasks = make([]*models.Task, 0)
-
-	for rows.Next() {
 
-		t := &models.Task{}
-		err := rows.Scan(&t.ID, &t.Date, &t.Title, &t.Comment, &t.Repeat)
+	// One extra row was requested to find out whether another page exists
+	if len(resp.Tasks) > filter.Limit {
+		resp.Tasks = resp.Tasks[:filter.Limit]
+		next, err := cursorAfter(resp.Tasks[len(resp.Tasks)-1])
 		if err != nil {
-			log.Printf("ERROR: Failed to scan task row in GetTasksByTitle: %v", err)
+			log.Printf("ERROR: Failed to build next cursor: %v", err)
 			return TasksResp{}, err
 		}
-		resp.Tasks = append(resp.Tasks, t)
+		resp.Next = next.Encode()
 	}
 
-	if err = rows.Err(); err != nil {
-		log.Printf("ERROR: Row iteration error in GetTasksByTitle: %v", err)
-		return TasksResp{}, err
-	}
-	log.Printf("DEBUG: Found %d tasks matching search: '%s'", len(resp.Tasks), search)
+	log.Printf("DEBUG: Retrieved %d tasks, has next page: %t", len(resp.Tasks), resp.Next != "")
 	return resp, nil
+}
+
+// GetTask retrieves single task by ID
+// id - task identifier
+func (s *Storage) GetTask(ctx context.Context, id string) (*models.Task, error) {
+	log.Printf("DEBUG: Getting task by ID: %s", id)
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
 
+	task, err := getTask(ctx, s.db, id)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("DEBUG: Task retrieved successfully, ID: %s", id)
+	return task, nil
 }
 
-// GetTasksByDate retrieves tasks for specific date
-// limit - maximum number of tasks to return
-// date - target date in YYYYMMDD format
-func (s *Storage) GetTasksByDate(limit int, date string) (TasksResp, error) {
-	log.Printf("DEBUG: Getting tasks for date: %s, limit: %d", date, limit)
-	rows, err := s.db.Query(`
-        SELECT id, date, title, comment, repeat 
-		FROM scheduler
-        WHERE date = :date
-		ORDER BY date ASC
-		LIMIT :limit
+// getTask reads task visible to the context user by ID using q, which may be a transaction
+// Completed tasks are read too, tasks in the trash are not
+func getTask(ctx context.Context, q querier, id string) (*models.Task, error) {
+	task, err := scanTask(q.QueryRowContext(ctx, `
+        SELECT `+taskColumns+`
+        FROM scheduler
+        WHERE id = :id AND deleted_at = '' AND `+visibleTasks+`
     `,
-		sql.Named("date", date),
-		sql.Named("limit", limit))
+		sql.Named("id", id),
+		sql.Named("owner_id", ownerID(ctx))))
+	if err == sql.ErrNoRows {
+		log.Printf("WARN: Task not found, ID: %s", id)
+		return nil, notFound("task", id)
+	}
 	if err != nil {
-		log.Printf("ERROR: Database error in GetTasksByDate: %v", err)
-		return TasksResp{}, err
+		log.Printf("ERROR: Database error reading task %s: %v", id, err)
+		return nil, err
 	}
-	defer rows.Close()
+	return task, nil
+}
 
-	var resp TasksResp
-	resp.Tasks = make([]*models.Task, 0)
+// UpdateTask updates existing task, the revision is recorded in the same transaction
+// task - task data with ID and expected Version, empty Version skips the check
+// action - revision action, models.ActionUpdate or models.ActionRevert
+// actor - who changed the task, stored in the revision
+// Returns task state before the update, on version mismatch returns
+// the current state together with ErrConflict. task.Version is set to the new version
+func (s *Storage) UpdateTask(ctx context.Context, task *models.Task, action, actor string) (*models.Task, error) {
+	log.Printf("DEBUG: Updating task, ID: %s", task.ID)
 
-	for rows.Next() {
-		t := &models.Task{}
-		err := rows.Scan(&t.ID, &t.Date, &t.Title, &t.Comment, &t.Repeat)
-		if err != nil {
-			log.Printf("ERROR: Failed to scan task row in GetTasksByDate: %v", err)
-			return TasksResp{}, err
-		}
-		resp.Tasks = append(resp.Tasks, t)
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("ERROR: Failed to begin transaction in UpdateTask: %v", err)
+		return nil, err
 	}
+	defer tx.Rollback()
 
-	if err = rows.Err(); err != nil {
-		log.Printf("ERROR: Row iteration error in GetTasksByDate: %v", err)
-		return TasksResp{}, err
+	before, err := updateTask(ctx, tx, task, action, actor)
+	if err != nil {
+		return before, err
 	}
-	log.Printf("DEBUG: Retrieved %d tasks for date: %s", len(resp.Tasks), date)
-	return resp, nil
 
+	if err := tx.Commit(); err != nil {
+		log.Printf("ERROR: Failed to commit UpdateTask for ID %s: %v", task.ID, err)
+		return nil, err
+	}
+	log.Printf("INFO: Task updated successfully, ID: %s", task.ID)
+	return before, nil
 }
 
-// GetTask retrieves single task by ID
-// id - task identifier
-func (s *Storage) GetTask(id string) (*models.Task, error) {
-	log.Printf("DEBUG: Getting task by ID: %s", id)
+// updateTask checks version, updates task and records its revision using q, which must be a transaction
+// Users the task is shared with for editing change it like the owner, except for the assignee
+func updateTask(ctx context.Context, q querier, task *models.Task, action, actor string) (*models.Task, error) {
+	before, err := getTaskWith(ctx, q, task.ID, models.PermissionEdit)
+	if err != nil {
+		return nil, err
+	}
 
-	result := s.db.QueryRow(`
-        SELECT id, date, title, comment, repeat 
-		FROM scheduler
-        WHERE id = :id
-    `,
-		sql.Named("id", id))
+	if task.Version != "" && task.Version != before.Version {
+		log.Printf("WARN: Task %s was modified concurrently, expected version %s, current %s", task.ID, task.Version, before.Version)
+		return before, fmt.Errorf("task with id=%s %w: version %s is outdated, current version is %s",
+			task.ID, ErrConflict, task.Version, before.Version)
+	}
 
-	var task models.Task
-	err := result.Scan(&task.ID, &task.Date, &task.Title, &task.Comment, &task.Repeat)
+	if task.Priority == "" {
+		task.Priority = before.Priority
+	}
+	priority, err := priorityLevel(task.Priority)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			log.Printf("WARN: Task not found, ID: %s", id)
-		} else {
-			log.Printf("ERROR: Database error in GetTask for ID %s: %v", id, err)
-		}
 		return nil, err
 	}
 
-	log.Printf("DEBUG: Task retrieved successfully, ID: %s", id)
+	current := ""
+	if before.Assignee != nil {
+		current = *before.Assignee
+	}
+	if task.Assignee == nil {
+		task.Assignee = before.Assignee
+	} else if !strings.EqualFold(strings.TrimSpace(*task.Assignee), current) && before.Permission != models.PermissionOwner {
+		log.Printf("WARN: User %s may not reassign task %s", ownerID(ctx), task.ID)
+		return nil, forbidden("task", task.ID, models.PermissionOwner)
+	}
+	assignee, err := assigneeArg(ctx, q, task.Assignee)
+	if err != nil {
+		return nil, err
+	}
 
-	return &task, nil
+	// Tags, project and blockers of a shared task belong to its owner
+	ctx = ownerScope(ctx, before)
 
-}
-
-// UpdateTask updates existing task
-// task - task data with ID
-func (s *Storage) UpdateTask(task *models.Task) error {
-	log.Printf("DEBUG: Updating task, ID: %s", task.ID)
+	if task.Project == nil {
+		task.Project = before.Project
+	} else if projectArg(task.Project) != projectArg(before.Project) {
+		if err := checkProject(ctx, q, task.Project); err != nil {
+			return nil, err
+		}
+	}
 
-	resalt, err := s.db.Exec(`
+	err = q.QueryRowContext(ctx, `
         UPDATE scheduler
         SET date = :date,
+            start = :start,
+            snoozed_from = CASE WHEN date = :date AND repeat = :repeat THEN snoozed_from ELSE '' END,
             title = :title,
             comment = :comment,
-            repeat = :repeat
+            repeat = :repeat,
+            priority = :priority,
+            project_id = :project_id,
+            assignee_id = :assignee_id,
+            version = version + 1
         WHERE id = :id
+        RETURNING version
     `,
 		sql.Named("id", task.ID),
+		sql.Named("assignee_id", assignee),
 		sql.Named("date", task.Date),
+		sql.Named("start", task.Start),
 		sql.Named("title", task.Title),
 		sql.Named("comment", task.Comment),
-		sql.Named("repeat", task.Repeat))
+		sql.Named("repeat", task.Repeat),
+		sql.Named("priority", priority),
+		sql.Named("project_id", projectArg(task.Project))).Scan(&task.Version)
 
 	if err != nil {
 		log.Printf("ERROR: Database error in UpdateTask for ID %s: %v", task.ID, err)
-		return err
+		return nil, mapError(err)
 	}
 
-	count, err := resalt.RowsAffected()
-	if err != nil {
-		log.Printf("ERROR: Failed to get rows affected in UpdateTask: %v", err)
-		return err
+	if task.Tags == nil {
+		task.Tags = before.Tags
+	} else if err := setTaskTags(ctx, q, task); err != nil {
+		return nil, err
 	}
-	if count == 0 {
-		log.Printf("WARN: Task not found for update, ID: %s", task.ID)
-		return fmt.Errorf("task with id=%s not found (nothing updated)", task.ID)
+
+	if task.BlockedBy == nil {
+		task.BlockedBy = before.BlockedBy
+	} else if err := setTaskBlockers(ctx, q, task, before.BlockedBy); err != nil {
+		return nil, err
 	}
-	log.Printf("INFO: Task updated successfully, ID: %s", task.ID)
-	return nil
+
+	if _, err := addRevision(ctx, q, task.ID, action, actor, before, task); err != nil {
+		return nil, err
+	}
+	return before, nil
 }
 
-// UpdateTaskDate updates only task date
+// DeleteTask moves task to the trash, "delete" revision is recorded in the same transaction
 // id - task identifier
-// date - new date in YYYYMMDD format
-func (s *Storage) UpdateTaskDate(id, date string) error {
-	log.Printf("DEBUG: Updating task date, ID: %s, new date: %s", id, date)
+// actor - who deleted the task, stored in the revision
+// Returns the deleted task
+func (s *Storage) DeleteTask(ctx context.Context, id, actor string) (*models.Task, error) {
+	log.Printf("DEBUG: Moving task to trash, ID: %s", id)
 
-	resalt, err := s.db.Exec(`
-        UPDATE scheduler
-        SET date = :date
-        WHERE id = :id
-    `,
-		sql.Named("date", date),
-		sql.Named("id", id))
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
 
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		log.Printf("ERROR: Database error in UpdateTaskDate for ID %s: %v", id, err)
-		return err
+		log.Printf("ERROR: Failed to begin transaction in DeleteTask: %v", err)
+		return nil, err
 	}
+	defer tx.Rollback()
 
-	count, err := resalt.RowsAffected()
+	task, err := deleteTask(ctx, tx, id, actor)
 	if err != nil {
-		log.Printf("ERROR: Failed to get rows affected in UpdateTaskDate: %v", err)
-		return err
+		return nil, err
 	}
-	if count == 0 {
-		log.Printf("WARN: Task not found for date update, ID: %s", id)
-		return fmt.Errorf("task with id=%s not found (nothing updated)", id)
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("ERROR: Failed to commit DeleteTask for ID %s: %v", id, err)
+		return nil, err
 	}
-	log.Printf("INFO: Task date updated successfully, ID: %s, new date: %s", id, date)
-	return nil
+	return task, nil
 }
 
-// DeleteTask removes task from scheduler
-// id - task identifier
-func (s *Storage) DeleteTask(id string) error {
-	log.Printf("DEBUG: Deleting task, ID: %s", id)
+// deleteTask moves task to the trash and records its revision using q, which must be a transaction
+// Only the owner deletes the task
+func deleteTask(ctx context.Context, q querier, id, actor string) (*models.Task, error) {
+	if _, err := getTaskWith(ctx, q, id, models.PermissionOwner); err != nil {
+		return nil, err
+	}
 
-	resalt, err := s.db.Exec(`
-        DELETE FROM scheduler
-        WHERE id = :id
+	task, err := scanTask(q.QueryRowContext(ctx, `
+        UPDATE scheduler
+        SET deleted_at = :deleted_at,
+            version = version + 1
+        WHERE id = :id AND deleted_at = '' AND owner_id = :owner_id
+        RETURNING `+taskColumns+`
     `,
-		sql.Named("id", id))
+		sql.Named("deleted_at", time.Now().UTC().Format(time.RFC3339)),
+		sql.Named("id", id),
+		sql.Named("owner_id", ownerID(ctx))))
 
+	if err == sql.ErrNoRows {
+		log.Printf("WARN: Task not found for deletion, ID: %s", id)
+		return nil, notFound("task", id)
+	}
 	if err != nil {
 		log.Printf("ERROR: Database error in DeleteTask for ID %s: %v", id, err)
-		return err
+		return nil, err
 	}
 
-	count, err := resalt.RowsAffected()
-	if err != nil {
-		log.Printf("ERROR: Failed to get rows affected in DeleteTask: %v", err)
-		return err
-	}
-	if count == 0 {
-		log.Printf("WARN: Task not found for deletion, ID: %s", id)
-		return fmt.Errorf("task with id=%s not found (nothing deleted)", id)
+	before := *task
+	before.DeletedAt = ""
+	if _, err := addRevision(ctx, q, id, models.ActionDelete, actor, &before, task); err != nil {
+		return nil, err
 	}
 
-	log.Printf("INFO: Task deleted successfully, ID: %s", id)
-	return nil
+	log.Printf("INFO: Task moved to trash, ID: %s", id)
+	return task, nil
 }
@@ -0,0 +1,257 @@
+package db
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	models "todo/pkg/models"
+)
+
+// blockersColumns selects comma separated blocker IDs of the scheduler row
+// and whether any of the blockers is still active
+const blockersColumns = `(
+            SELECT coalesce(group_concat(blocker_id, ',' ORDER BY blocker_id), '')
+            FROM task_dependencies WHERE task_id = scheduler.id
+        ), EXISTS (
+            SELECT 1 FROM task_dependencies d JOIN scheduler b ON b.id = d.blocker_id
+            WHERE d.task_id = scheduler.id AND b.deleted_at = '' AND b.completed_at = ''
+        )`
+
+// splitBlockers parses blocker IDs selected with blockersColumns
+func splitBlockers(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+// setTaskBlockers replaces blockers of the task using q, which must be a transaction
+// Blockers the task did not have yet must be active tasks, a blocker that depends
+// on the task itself, directly or through other tasks, is rejected as a cycle.
+// task.BlockedBy is replaced with the stored IDs
+func setTaskBlockers(ctx context.Context, q querier, task *models.Task, current []string) error {
+	ids := make([]string, 0, len(task.BlockedBy))
+	for _, s := range task.BlockedBy {
+		n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+		if err != nil || n <= 0 {
+			return &ValidationError{Field: "blocked_by", Message: "invalid task id: " + s}
+		}
+		id := strconv.FormatInt(n, 10)
+		if id == task.ID {
+			return &ValidationError{Field: "blocked_by", Message: "task cannot block itself"}
+		}
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+
+	_, err := q.ExecContext(ctx, `DELETE FROM task_dependencies WHERE task_id = :task_id`, sql.Named("task_id", task.ID))
+	if err != nil {
+		log.Printf("ERROR: Failed to clear blockers of task %s: %v", task.ID, err)
+		return err
+	}
+
+	for _, id := range ids {
+		if !slices.Contains(current, id) {
+			blocker, err := getTask(ctx, q, id)
+			if errors.Is(err, ErrNotFound) {
+				return &ValidationError{Field: "blocked_by", Message: "blocking task not found: " + id}
+			} else if err != nil {
+				return err
+			}
+			if blocker.CompletedAt != "" {
+				return &ValidationError{Field: "blocked_by", Message: "blocking task is already done: " + id}
+			}
+		}
+
+		cycle, err := dependsOn(ctx, q, id, task.ID)
+		if err != nil {
+			return err
+		}
+		if cycle {
+			log.Printf("WARN: Dependency cycle rejected, task %s is blocked by %s", id, task.ID)
+			return &ValidationError{Field: "blocked_by", Message: fmt.Sprintf("dependency cycle: task %s is already blocked by task %s", id, task.ID)}
+		}
+
+		_, err = q.ExecContext(ctx, `
+            INSERT INTO task_dependencies (task_id, blocker_id) VALUES (:task_id, :blocker_id)
+        `, sql.Named("task_id", task.ID), sql.Named("blocker_id", id))
+		if err != nil {
+			log.Printf("ERROR: Failed to add blocker %s of task %s: %v", id, task.ID, err)
+			return mapError(err)
+		}
+	}
+
+	slices.SortFunc(ids, func(a, b string) int {
+		x, _ := strconv.ParseInt(a, 10, 64)
+		y, _ := strconv.ParseInt(b, 10, 64)
+		return cmp.Compare(x, y)
+	})
+	if len(ids) == 0 {
+		ids = nil
+	}
+	task.BlockedBy = ids
+	return nil
+}
+
+// dependsOn reports whether task id is blocked by blocker directly or through other tasks
+func dependsOn(ctx context.Context, q querier, id, blocker string) (bool, error) {
+	var found bool
+	err := q.QueryRowContext(ctx, `
+        WITH RECURSIVE chain(id) AS (
+            SELECT CAST(:id AS INTEGER)
+            UNION
+            SELECT d.blocker_id FROM task_dependencies d JOIN chain c ON d.task_id = c.id
+        )
+        SELECT EXISTS (SELECT 1 FROM chain WHERE id = CAST(:blocker AS INTEGER))
+    `, sql.Named("id", id), sql.Named("blocker", blocker)).Scan(&found)
+	if err != nil {
+		log.Printf("ERROR: Database error checking dependencies of task %s: %v", id, err)
+		return false, err
+	}
+	return found, nil
+}
+
+// releaseDependents removes the completed task from blockers of other tasks using q,
+// which must be a transaction. Versions of the released tasks are incremented
+func releaseDependents(ctx context.Context, q querier, id string) error {
+	_, err := q.ExecContext(ctx, `
+        UPDATE scheduler
+        SET version = version + 1
+        WHERE id IN (SELECT task_id FROM task_dependencies WHERE blocker_id = :id)
+    `, sql.Named("id", id))
+	if err == nil {
+		_, err = q.ExecContext(ctx, `DELETE FROM task_dependencies WHERE blocker_id = :id`, sql.Named("id", id))
+	}
+	if err != nil {
+		log.Printf("ERROR: Failed to release tasks blocked by %s: %v", id, err)
+		return err
+	}
+	return nil
+}
+
+// PlanFilter describes which tasks GetPlan returns
+type PlanFilter struct {
+	Task    string // ID of the task to plan, the plan holds the task and everything blocking it
+	Project string // ID of the project the tasks belong to, ignored when Task is set
+}
+
+// GetPlan retrieves active tasks visible to the context user in dependency order: every task follows all its blockers
+// Tasks which do not depend on each other keep the (date, priority, id) order,
+// undated tasks are planned only as blockers of filter.Task
+func (s *Storage) GetPlan(ctx context.Context, filter PlanFilter) (TasksResp, error) {
+	log.Printf("DEBUG: Building plan, task: '%s', project: '%s'", filter.Task, filter.Project)
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	where := "deleted_at = '' AND completed_at = '' AND " + visibleTasks
+	args := []any{sql.Named("owner_id", ownerID(ctx))}
+	switch {
+	case filter.Task != "":
+		if _, err := getTask(ctx, s.db, filter.Task); err != nil {
+			return TasksResp{}, err
+		}
+		where += ` AND id IN (
+            WITH RECURSIVE chain(id) AS (
+                SELECT CAST(:task AS INTEGER)
+                UNION
+                SELECT d.blocker_id FROM task_dependencies d JOIN chain c ON d.task_id = c.id
+            )
+            SELECT id FROM chain
+        )`
+		args = append(args, sql.Named("task", filter.Task))
+	case filter.Project != "":
+		where += " AND date != '' AND project_id = :project_id"
+		args = append(args, sql.Named("project_id", filter.Project))
+	default:
+		where += " AND date != ''"
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+        SELECT `+taskColumns+`
+        FROM scheduler
+        WHERE `+where+`
+        ORDER BY `+taskOrders[OrderByDate].by, args...)
+	if err != nil {
+		log.Printf("ERROR: Database error in GetPlan: %v", err)
+		return TasksResp{}, err
+	}
+	defer rows.Close()
+
+	var tasks []*models.Task
+	for rows.Next() {
+		t, err := scanTask(rows)
+		if err != nil {
+			log.Printf("ERROR: Failed to scan task row: %v", err)
+			return TasksResp{}, err
+		}
+		tasks = append(tasks, t)
+	}
+	if err = rows.Err(); err != nil {
+		log.Printf("ERROR: Row iteration error in GetPlan: %v", err)
+		return TasksResp{}, err
+	}
+
+	plan, err := sortByDependencies(tasks)
+	if err != nil {
+		log.Printf("ERROR: Failed to order plan: %v", err)
+		return TasksResp{}, err
+	}
+
+	log.Printf("DEBUG: Plan built, %d tasks", len(plan))
+	return TasksResp{Tasks: plan}, nil
+}
+
+// sortByDependencies orders tasks topologically, blockers outside of the list are ignored
+// Among tasks ready at the same time the one earlier in the input goes first
+func sortByDependencies(tasks []*models.Task) ([]*models.Task, error) {
+	index := make(map[string]int, len(tasks))
+	for i, t := range tasks {
+		index[t.ID] = i
+	}
+
+	waiting := make([]int, len(tasks))      // number of blockers not placed yet
+	dependents := make([][]int, len(tasks)) // tasks blocked by the task
+	for i, t := range tasks {
+		for _, b := range t.BlockedBy {
+			if j, ok := index[b]; ok {
+				waiting[i]++
+				dependents[j] = append(dependents[j], i)
+			}
+		}
+	}
+
+	var ready []int
+	for i := range tasks {
+		if waiting[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	plan := make([]*models.Task, 0, len(tasks))
+	for len(ready) > 0 {
+		i := ready[0]
+		ready = ready[1:]
+		plan = append(plan, tasks[i])
+
+		for _, j := range dependents[i] {
+			waiting[j]--
+			if waiting[j] == 0 {
+				pos, _ := slices.BinarySearch(ready, j)
+				ready = slices.Insert(ready, pos, j)
+			}
+		}
+	}
+
+	if len(plan) != len(tasks) {
+		return nil, fmt.Errorf("dependency cycle among %d tasks", len(tasks)-len(plan))
+	}
+	return plan, nil
+}
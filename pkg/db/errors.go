@@ -0,0 +1,59 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// Sentinel errors returned by storage methods, check them with errors.Is
+var (
+	// ErrNotFound - requested record does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrConflict - change clashes with the current state of the data
+	ErrConflict = errors.New("conflict")
+	// ErrForbidden - the record is visible to the user, but the change needs more rights
+	ErrForbidden = errors.New("forbidden")
+)
+
+// ValidationError reports input rejected by the storage layer
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return e.Field + ": " + e.Message
+}
+
+// notFound builds ErrNotFound error for the entity with given id
+func notFound(entity, id string) error {
+	return fmt.Errorf("%s with id=%s %w", entity, id, ErrNotFound)
+}
+
+// forbidden builds ErrForbidden error for the entity with given id
+func forbidden(entity, id, permission string) error {
+	return fmt.Errorf("%w: %s with id=%s requires %s permission", ErrForbidden, entity, id, permission)
+}
+
+// mapError translates SQLite constraint violations into storage errors
+// Other errors are returned unchanged
+func mapError(err error) error {
+	var serr *sqlite.Error
+	if !errors.As(err, &serr) {
+		return err
+	}
+
+	switch serr.Code() {
+	case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+		return fmt.Errorf("%w: %s", ErrConflict, serr.Error())
+	case sqlite3.SQLITE_CONSTRAINT_CHECK, sqlite3.SQLITE_CONSTRAINT_NOTNULL, sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+		return &ValidationError{Message: serr.Error()}
+	}
+	return err
+}
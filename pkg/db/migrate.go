@@ -0,0 +1,113 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// migrations holds numbered schema changes applied on top of schema.sql
+// File names start with the version number: 0001_name.sql
+//
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// migrate applies migrations newer than the database user_version
+// Each migration runs in its own transaction together with the version bump.
+// Foreign keys are disabled while migrations run, so tables can be rebuilt
+// without cascading deletes, and checked before each migration is committed
+func (s *Storage) migrate() error {
+	ctx := context.Background()
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var version int
+	if err := conn.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("read schema version: %w", err)
+	}
+
+	entries, err := fs.ReadDir(migrations, "migrations")
+	if err != nil {
+		return err
+	}
+
+	if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+		return err
+	}
+	if s.config.ForeignKeys {
+		defer conn.ExecContext(ctx, "PRAGMA foreign_keys = ON")
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		num, _, _ := strings.Cut(name, "_")
+		n, err := strconv.Atoi(num)
+		if err != nil {
+			return fmt.Errorf("invalid migration name %s", name)
+		}
+		if n <= version {
+			continue
+		}
+		if n != version+1 {
+			return fmt.Errorf("migration %s is out of sequence, schema version is %d", name, version)
+		}
+
+		body, err := migrations.ReadFile("migrations/" + name)
+		if err != nil {
+			return err
+		}
+
+		log.Printf("INFO: Applying database migration %s", name)
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(string(body)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %s: %w", name, err)
+		}
+		if err := checkForeignKeys(tx); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %s: %w", name, err)
+		}
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", n)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %s: %w", name, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("migration %s: %w", name, err)
+		}
+		version = n
+	}
+
+	log.Printf("DEBUG: Database schema version: %d", version)
+	return nil
+}
+
+// checkForeignKeys fails when a row refers to a missing parent row
+func checkForeignKeys(tx *sql.Tx) error {
+	rows, err := tx.Query("PRAGMA foreign_key_check")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		var table, parent string
+		var rowid sql.NullInt64
+		var fkid int
+		if err := rows.Scan(&table, &rowid, &parent, &fkid); err != nil {
+			return err
+		}
+		return fmt.Errorf("row %d of %s refers to missing %s row", rowid.Int64, table, parent)
+	}
+	return rows.Err()
+}
@@ -0,0 +1,4 @@
+-- Soft delete: tasks are moved to the trash instead of being removed
+ALTER TABLE scheduler ADD COLUMN deleted_at VARCHAR(32) NOT NULL DEFAULT '';
+
+CREATE INDEX idx_deleted_at ON scheduler(deleted_at);
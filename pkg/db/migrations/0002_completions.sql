@@ -0,0 +1,13 @@
+-- Completion log: one row per "done" click
+-- task_id has no foreign key and title is copied so the log outlives purged tasks
+CREATE TABLE completions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    task_id INTEGER NOT NULL,
+    title VARCHAR(255) NOT NULL DEFAULT '',
+    date CHAR(8) NOT NULL DEFAULT '',
+    completed_at VARCHAR(32) NOT NULL DEFAULT '',
+    note TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_completions_task ON completions(task_id);
+CREATE INDEX idx_completions_date ON completions(date);
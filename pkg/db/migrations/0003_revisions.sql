@@ -0,0 +1,13 @@
+-- Audit trail: every change of a task is stored as a revision
+-- task_id has no foreign key so the history outlives purged tasks
+CREATE TABLE revisions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    task_id INTEGER NOT NULL,
+    action VARCHAR(16) NOT NULL DEFAULT '',
+    actor VARCHAR(255) NOT NULL DEFAULT '',
+    changes TEXT NOT NULL DEFAULT '{}',
+    snapshot TEXT NOT NULL DEFAULT '{}',
+    created_at VARCHAR(32) NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_revisions_task ON revisions(task_id);
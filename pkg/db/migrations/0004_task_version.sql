@@ -0,0 +1,2 @@
+-- Version of the task row, incremented on every change, used for optimistic locking
+ALTER TABLE scheduler ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,15 @@
+-- Tags of tasks, many-to-many relation with scheduler
+CREATE TABLE tags (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(64) NOT NULL,
+    -- lower-cased name, NOCASE folds ASCII only
+    key VARCHAR(64) NOT NULL UNIQUE
+);
+
+CREATE TABLE task_tags (
+    task_id INTEGER NOT NULL REFERENCES scheduler(id) ON DELETE CASCADE,
+    tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (task_id, tag_id)
+);
+
+CREATE INDEX idx_task_tags_tag_id ON task_tags(tag_id);
@@ -0,0 +1,12 @@
+-- Projects group tasks into separate lists
+CREATE TABLE projects (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(128) NOT NULL,
+    color VARCHAR(7) NOT NULL DEFAULT '',
+    archived INTEGER NOT NULL DEFAULT 0
+);
+
+-- Project of the task, NULL for tasks outside any project
+ALTER TABLE scheduler ADD COLUMN project_id INTEGER REFERENCES projects(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_scheduler_project_id ON scheduler(project_id);
@@ -0,0 +1,5 @@
+-- Priority of the task from 1 (P1, the most urgent) to 4 (P4, default)
+ALTER TABLE scheduler ADD COLUMN priority INTEGER NOT NULL DEFAULT 4 CHECK (priority BETWEEN 1 AND 4);
+
+CREATE INDEX idx_scheduler_date_priority ON scheduler(date, priority);
+CREATE INDEX idx_scheduler_priority_date ON scheduler(priority, date);
@@ -0,0 +1,10 @@
+-- Ordered checklist items of a task, each with its own done state
+CREATE TABLE checklist_items (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    task_id INTEGER NOT NULL REFERENCES scheduler(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    done INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_checklist_items_task_id ON checklist_items(task_id, position);
@@ -0,0 +1,13 @@
+-- Files attached to tasks, content is stored on disk under its sha256
+CREATE TABLE attachments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    task_id INTEGER NOT NULL REFERENCES scheduler(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(255) NOT NULL DEFAULT '',
+    size INTEGER NOT NULL,
+    sha256 CHAR(64) NOT NULL,
+    created_at TEXT NOT NULL
+);
+
+CREATE INDEX idx_attachments_task_id ON attachments(task_id);
+CREATE INDEX idx_attachments_sha256 ON attachments(sha256);
@@ -0,0 +1,9 @@
+-- "Blocked by" relations between tasks, the task waits until its blockers are done
+CREATE TABLE task_dependencies (
+    task_id INTEGER NOT NULL REFERENCES scheduler(id) ON DELETE CASCADE,
+    blocker_id INTEGER NOT NULL REFERENCES scheduler(id) ON DELETE CASCADE,
+    PRIMARY KEY (task_id, blocker_id),
+    CHECK (task_id <> blocker_id)
+);
+
+CREATE INDEX idx_task_dependencies_blocker_id ON task_dependencies(blocker_id);
@@ -0,0 +1,35 @@
+-- User accounts, every task and everything attached to it belongs to one user
+-- Account 1 owns data created before accounts existed, its password comes from TODO_PASSWORD
+CREATE TABLE users (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    login VARCHAR(64) NOT NULL UNIQUE COLLATE NOCASE,
+    password_hash VARCHAR(255) NOT NULL DEFAULT '',
+    admin INTEGER NOT NULL DEFAULT 0,
+    created_at VARCHAR(32) NOT NULL DEFAULT ''
+);
+
+INSERT INTO users (id, login, admin, created_at) VALUES (1, 'admin', 1, strftime('%Y-%m-%dT%H:%M:%SZ', 'now'));
+
+ALTER TABLE scheduler ADD COLUMN owner_id INTEGER NOT NULL DEFAULT 1 REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE projects ADD COLUMN owner_id INTEGER NOT NULL DEFAULT 1 REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE completions ADD COLUMN owner_id INTEGER NOT NULL DEFAULT 1 REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE revisions ADD COLUMN owner_id INTEGER NOT NULL DEFAULT 1 REFERENCES users(id) ON DELETE CASCADE;
+
+CREATE INDEX idx_scheduler_owner_id ON scheduler(owner_id, date);
+CREATE INDEX idx_projects_owner_id ON projects(owner_id);
+CREATE INDEX idx_completions_owner_id ON completions(owner_id);
+CREATE INDEX idx_revisions_owner_id ON revisions(owner_id);
+
+-- Tag names are unique per user, the table is rebuilt to change the constraint
+CREATE TABLE tags_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    owner_id INTEGER NOT NULL DEFAULT 1 REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(64) NOT NULL,
+    -- lower-cased name, NOCASE folds ASCII only
+    key VARCHAR(64) NOT NULL,
+    UNIQUE (owner_id, key)
+);
+
+INSERT INTO tags_new (id, owner_id, name, key) SELECT id, 1, name, key FROM tags;
+DROP TABLE tags;
+ALTER TABLE tags_new RENAME TO tags;
@@ -0,0 +1,6 @@
+-- Roles replace the admin flag: admin manages users and backups, editor changes tasks, readonly only reads
+ALTER TABLE users ADD COLUMN role VARCHAR(16) NOT NULL DEFAULT 'editor' CHECK (role IN ('admin', 'editor', 'readonly'));
+
+UPDATE users SET role = 'admin' WHERE admin = 1;
+
+ALTER TABLE users DROP COLUMN admin;
@@ -0,0 +1,14 @@
+-- Task assignment and sharing between users
+-- The assignee and users the task is shared with for editing may change it, view shares only read it
+ALTER TABLE scheduler ADD COLUMN assignee_id INTEGER REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_scheduler_assignee_id ON scheduler(assignee_id);
+
+CREATE TABLE task_shares (
+    task_id INTEGER NOT NULL REFERENCES scheduler(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    permission VARCHAR(8) NOT NULL CHECK (permission IN ('view', 'edit')),
+    PRIMARY KEY (task_id, user_id)
+);
+
+CREATE INDEX idx_task_shares_user_id ON task_shares(user_id);
@@ -0,0 +1,2 @@
+-- Start (defer until) date, the task is hidden from the agenda before it; date is the due date
+ALTER TABLE scheduler ADD COLUMN start CHAR(8) NOT NULL DEFAULT '';
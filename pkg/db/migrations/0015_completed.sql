@@ -0,0 +1,12 @@
+-- Completed one-time tasks stay in the scheduler with completion time instead of going to the trash
+ALTER TABLE scheduler ADD COLUMN completed_at VARCHAR(32) NOT NULL DEFAULT '';
+
+-- Tasks moved to the trash by completion are brought back as completed
+UPDATE scheduler
+SET completed_at = deleted_at,
+    deleted_at = ''
+WHERE deleted_at != '' AND repeat = '' AND (
+    SELECT action FROM revisions WHERE task_id = scheduler.id ORDER BY id DESC LIMIT 1
+) = 'done';
+
+CREATE INDEX idx_completed_at ON scheduler(completed_at);
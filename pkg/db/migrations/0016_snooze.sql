@@ -0,0 +1,2 @@
+-- Date of the snoozed occurrence of a recurring task, the series goes on from it when the task is done
+ALTER TABLE scheduler ADD COLUMN snoozed_from CHAR(8) NOT NULL DEFAULT '';
@@ -0,0 +1,315 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"regexp"
+	"strings"
+	models "todo/pkg/models"
+)
+
+// maxProjectName - longest accepted project name
+const maxProjectName = 128
+
+// projectColor matches #rrggbb colors
+var projectColor = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// projectArg converts project reference of a task to the project_id value
+func projectArg(project *string) any {
+	if project == nil || *project == "" {
+		return nil
+	}
+	return *project
+}
+
+// checkProject verifies that the task may be put into the project
+// nil or empty project means no project
+func checkProject(ctx context.Context, q querier, project *string) error {
+	if projectArg(project) == nil {
+		return nil
+	}
+
+	var archived bool
+	err := q.QueryRowContext(ctx, `SELECT archived FROM projects WHERE id = :id AND owner_id = :owner_id`,
+		sql.Named("id", *project),
+		sql.Named("owner_id", ownerID(ctx))).Scan(&archived)
+	if err == sql.ErrNoRows {
+		log.Printf("WARN: Project not found, ID: %s", *project)
+		return &ValidationError{Field: "project", Message: "project with id=" + *project + " not found"}
+	}
+	if err != nil {
+		log.Printf("ERROR: Database error reading project %s: %v", *project, err)
+		return err
+	}
+	if archived {
+		log.Printf("WARN: Project is archived, ID: %s", *project)
+		return &ValidationError{Field: "project", Message: "project with id=" + *project + " is archived"}
+	}
+	return nil
+}
+
+// validateProject trims project name and checks name and color
+func validateProject(p *models.Project) error {
+	p.Name = strings.TrimSpace(p.Name)
+	switch {
+	case p.Name == "":
+		return &ValidationError{Field: "name", Message: "the name is empty"}
+	case len([]rune(p.Name)) > maxProjectName:
+		return &ValidationError{Field: "name", Message: "the name is too long"}
+	case p.Color != "" && !projectColor.MatchString(p.Color):
+		return &ValidationError{Field: "color", Message: "color must be in #rrggbb format"}
+	}
+	return nil
+}
+
+// GetProjects retrieves projects of the context user sorted by name with active task counts
+// archived - include archived projects
+func (s *Storage) GetProjects(ctx context.Context, archived bool) ([]*models.Project, error) {
+	log.Printf("DEBUG: Getting projects, archived: %t", archived)
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+        SELECT p.id, p.name, p.color, p.archived,
+            (SELECT count(*) FROM scheduler s WHERE s.project_id = p.id AND s.deleted_at = '' AND s.completed_at = '')
+        FROM projects p
+        WHERE p.owner_id = :owner_id AND (p.archived = 0 OR :archived)
+        ORDER BY p.name, p.id
+    `, sql.Named("archived", archived), sql.Named("owner_id", ownerID(ctx)))
+	if err != nil {
+		log.Printf("ERROR: Database error in GetProjects: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	projects := make([]*models.Project, 0)
+	for rows.Next() {
+		p := &models.Project{}
+		if err := rows.Scan(&p.ID, &p.Name, &p.Color, &p.Archived, &p.Tasks); err != nil {
+			log.Printf("ERROR: Failed to scan project row: %v", err)
+			return nil, err
+		}
+		projects = append(projects, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Printf("ERROR: Row iteration error in GetProjects: %v", err)
+		return nil, err
+	}
+	log.Printf("DEBUG: Retrieved %d projects", len(projects))
+	return projects, nil
+}
+
+// GetProject retrieves single project by ID
+func (s *Storage) GetProject(ctx context.Context, id string) (*models.Project, error) {
+	log.Printf("DEBUG: Getting project by ID: %s", id)
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	var p models.Project
+	err := s.db.QueryRowContext(ctx, `
+        SELECT p.id, p.name, p.color, p.archived,
+            (SELECT count(*) FROM scheduler s WHERE s.project_id = p.id AND s.deleted_at = '' AND s.completed_at = '')
+        FROM projects p
+        WHERE p.id = :id AND p.owner_id = :owner_id
+    `, sql.Named("id", id), sql.Named("owner_id", ownerID(ctx))).Scan(&p.ID, &p.Name, &p.Color, &p.Archived, &p.Tasks)
+	if err == sql.ErrNoRows {
+		log.Printf("WARN: Project not found, ID: %s", id)
+		return nil, notFound("project", id)
+	}
+	if err != nil {
+		log.Printf("ERROR: Database error in GetProject for ID %s: %v", id, err)
+		return nil, err
+	}
+	return &p, nil
+}
+
+// AddProject creates a new project
+// Returns project ID or error
+func (s *Storage) AddProject(ctx context.Context, p *models.Project) (int64, error) {
+	log.Printf("DEBUG: Adding new project: %s", p.Name)
+
+	if err := validateProject(p); err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, `
+        INSERT INTO projects (name, color, archived, owner_id)
+        VALUES (:name, :color, :archived, :owner_id)
+    `,
+		sql.Named("name", p.Name),
+		sql.Named("color", p.Color),
+		sql.Named("archived", p.Archived),
+		sql.Named("owner_id", ownerID(ctx)))
+	if err != nil {
+		log.Printf("ERROR: Database error in AddProject: %v", err)
+		return 0, mapError(err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		log.Printf("ERROR: Failed to get last insert ID: %v", err)
+		return 0, err
+	}
+
+	log.Printf("INFO: Project created successfully, ID: %d, Name: %s", id, p.Name)
+	return id, nil
+}
+
+// UpdateProject changes name, color and archived flag of the project
+func (s *Storage) UpdateProject(ctx context.Context, p *models.Project) error {
+	log.Printf("DEBUG: Updating project, ID: %s", p.ID)
+
+	if err := validateProject(p); err != nil {
+		return err
+	}
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, `
+        UPDATE projects
+        SET name = :name,
+            color = :color,
+            archived = :archived
+        WHERE id = :id AND owner_id = :owner_id
+    `,
+		sql.Named("id", p.ID),
+		sql.Named("owner_id", ownerID(ctx)),
+		sql.Named("name", p.Name),
+		sql.Named("color", p.Color),
+		sql.Named("archived", p.Archived))
+	if err != nil {
+		log.Printf("ERROR: Database error in UpdateProject for ID %s: %v", p.ID, err)
+		return mapError(err)
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("ERROR: Failed to get rows affected in UpdateProject: %v", err)
+		return err
+	}
+	if count == 0 {
+		log.Printf("WARN: Project not found for update, ID: %s", p.ID)
+		return notFound("project", p.ID)
+	}
+
+	log.Printf("INFO: Project updated successfully, ID: %s", p.ID)
+	return nil
+}
+
+// DeleteProject removes the project, its tasks including trashed ones stay without project
+func (s *Storage) DeleteProject(ctx context.Context, id string) error {
+	log.Printf("DEBUG: Deleting project, ID: %s", id)
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("ERROR: Failed to begin transaction in DeleteProject: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	moved, err := tx.ExecContext(ctx, `
+        UPDATE scheduler
+        SET project_id = NULL,
+            version = version + 1
+        WHERE project_id = :id AND owner_id = :owner_id
+    `, sql.Named("id", id), sql.Named("owner_id", ownerID(ctx)))
+	if err != nil {
+		log.Printf("ERROR: Failed to detach tasks of project %s: %v", id, err)
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM projects WHERE id = :id AND owner_id = :owner_id`,
+		sql.Named("id", id), sql.Named("owner_id", ownerID(ctx)))
+	if err != nil {
+		log.Printf("ERROR: Database error in DeleteProject for ID %s: %v", id, err)
+		return err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("ERROR: Failed to get rows affected in DeleteProject: %v", err)
+		return err
+	}
+	if count == 0 {
+		log.Printf("WARN: Project not found for deletion, ID: %s", id)
+		return notFound("project", id)
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("ERROR: Failed to commit DeleteProject for ID %s: %v", id, err)
+		return err
+	}
+
+	tasks, _ := moved.RowsAffected()
+	log.Printf("INFO: Project deleted, ID: %s, tasks left without project: %d", id, tasks)
+	return nil
+}
+
+// MoveTask puts the task into the project, empty project removes it from its project
+// "update" revision is recorded in the same transaction, actor tells who moved the task
+// Returns task state before and after the move
+func (s *Storage) MoveTask(ctx context.Context, id, project, actor string) (before, after *models.Task, err error) {
+	log.Printf("DEBUG: Moving task %s to project '%s'", id, project)
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("ERROR: Failed to begin transaction in MoveTask: %v", err)
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	before, err = getTaskWith(ctx, tx, id, models.PermissionEdit)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkProject(ownerScope(ctx, before), tx, &project); err != nil {
+		return nil, nil, err
+	}
+
+	moved := *before
+	if project != "" {
+		moved.Project = &project
+	} else {
+		moved.Project = nil
+	}
+	after = &moved
+
+	err = tx.QueryRowContext(ctx, `
+        UPDATE scheduler
+        SET project_id = :project_id,
+            version = version + 1
+        WHERE id = :id
+        RETURNING version
+    `,
+		sql.Named("id", id),
+		sql.Named("project_id", projectArg(after.Project))).Scan(&after.Version)
+	if err != nil {
+		log.Printf("ERROR: Database error in MoveTask for ID %s: %v", id, err)
+		return nil, nil, mapError(err)
+	}
+	if _, err := addRevision(ctx, tx, id, models.ActionUpdate, actor, before, after); err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("ERROR: Failed to commit MoveTask for ID %s: %v", id, err)
+		return nil, nil, err
+	}
+
+	log.Printf("INFO: Task %s moved to project '%s'", id, project)
+	return before, after, nil
+}
@@ -0,0 +1,196 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"strings"
+	"time"
+	models "todo/pkg/models"
+)
+
+// RevisionsResp represents response structure for task history
+type RevisionsResp struct {
+	Revisions []*models.Revision `json:"revisions"`
+}
+
+// revisionFields lists task fields tracked in revision diffs
+var revisionFields = []struct {
+	name string
+	get  func(t *models.Task) string
+}{
+	{"date", func(t *models.Task) string { return t.Date }},
+	{"start", func(t *models.Task) string { return t.Start }},
+	{"title", func(t *models.Task) string { return t.Title }},
+	{"comment", func(t *models.Task) string { return t.Comment }},
+	{"repeat", func(t *models.Task) string { return t.Repeat }},
+	{"priority", func(t *models.Task) string { return t.Priority }},
+	{"tags", func(t *models.Task) string { return strings.Join(t.Tags, ",") }},
+	{"blocked_by", func(t *models.Task) string { return strings.Join(t.BlockedBy, ",") }},
+	{"project", func(t *models.Task) string {
+		if t.Project == nil {
+			return ""
+		}
+		return *t.Project
+	}},
+	{"assignee", func(t *models.Task) string {
+		if t.Assignee == nil {
+			return ""
+		}
+		return *t.Assignee
+	}},
+	{"completed_at", func(t *models.Task) string { return t.CompletedAt }},
+	{"deleted_at", func(t *models.Task) string { return t.DeletedAt }},
+}
+
+// diffTasks returns changed fields between two task states
+// nil before is treated as an empty task
+func diffTasks(before, after *models.Task) map[string]models.FieldChange {
+	changes := make(map[string]models.FieldChange)
+	if before == nil {
+		before = &models.Task{}
+	}
+
+	for _, f := range revisionFields {
+		if old, cur := f.get(before), f.get(after); old != cur {
+			changes[f.name] = models.FieldChange{Old: old, New: cur}
+		}
+	}
+	return changes
+}
+
+// addRevision records change of a task made by actor using q, which must be the transaction of the change
+// before - state prior to the change, nil for created tasks
+// after - state after the change, stored as revision snapshot
+// The record belongs to the owner of the task, the actor tells who made the change
+func addRevision(ctx context.Context, q querier, taskID, action, actor string, before, after *models.Task) (int64, error) {
+	changes, err := json.Marshal(diffTasks(before, after))
+	if err != nil {
+		return 0, err
+	}
+	state, err := json.Marshal(after)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := q.ExecContext(ctx, `
+        INSERT INTO revisions (task_id, action, actor, changes, snapshot, created_at, owner_id)
+        VALUES (:task_id, :action, :actor, :changes, :snapshot, :created_at,
+                ifnull((SELECT owner_id FROM scheduler WHERE id = :task_id), :owner_id))
+    `,
+		sql.Named("task_id", taskID),
+		sql.Named("action", action),
+		sql.Named("actor", actor),
+		sql.Named("changes", string(changes)),
+		sql.Named("snapshot", string(state)),
+		sql.Named("created_at", time.Now().UTC().Format(time.RFC3339)),
+		sql.Named("owner_id", ownerID(ctx)))
+
+	if err != nil {
+		log.Printf("ERROR: Database error recording revision of task %s: %v", taskID, err)
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		log.Printf("ERROR: Failed to get last insert ID: %v", err)
+		return 0, err
+	}
+
+	log.Printf("INFO: Revision recorded, ID: %d, task ID: %s, action: %s", id, taskID, action)
+	return id, nil
+}
+
+// GetRevisions retrieves history of a task, most recent first
+// taskID - task identifier
+// limit - maximum number of revisions to return
+func (s *Storage) GetRevisions(ctx context.Context, taskID string, limit int) (RevisionsResp, error) {
+	log.Printf("DEBUG: Getting revisions of task %s, limit: %d", taskID, limit)
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+        SELECT id, task_id, action, actor, changes, snapshot, created_at
+        FROM revisions
+        WHERE task_id = :task_id AND task_id IN (SELECT id FROM scheduler WHERE `+visibleTasks+`)
+        ORDER BY id DESC
+        LIMIT :limit
+    `,
+		sql.Named("task_id", taskID),
+		sql.Named("owner_id", ownerID(ctx)),
+		sql.Named("limit", limit))
+	if err != nil {
+		log.Printf("ERROR: Database error in GetRevisions: %v", err)
+		return RevisionsResp{}, err
+	}
+	defer rows.Close()
+
+	var resp RevisionsResp
+	resp.Revisions = make([]*models.Revision, 0)
+
+	for rows.Next() {
+		rev, err := scanRevision(rows)
+		if err != nil {
+			log.Printf("ERROR: Failed to scan revision row: %v", err)
+			return RevisionsResp{}, err
+		}
+		resp.Revisions = append(resp.Revisions, rev)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Printf("ERROR: Row iteration error in GetRevisions: %v", err)
+		return RevisionsResp{}, err
+	}
+	log.Printf("DEBUG: Retrieved %d revisions of task %s", len(resp.Revisions), taskID)
+	return resp, nil
+}
+
+// GetRevision retrieves single revision of a task
+// taskID - task identifier
+// id - revision identifier
+func (s *Storage) GetRevision(ctx context.Context, taskID, id string) (*models.Revision, error) {
+	log.Printf("DEBUG: Getting revision %s of task %s", id, taskID)
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	row := s.db.QueryRowContext(ctx, `
+        SELECT id, task_id, action, actor, changes, snapshot, created_at
+        FROM revisions
+        WHERE id = :id AND task_id = :task_id AND task_id IN (SELECT id FROM scheduler WHERE `+visibleTasks+`)
+    `,
+		sql.Named("id", id),
+		sql.Named("task_id", taskID),
+		sql.Named("owner_id", ownerID(ctx)))
+
+	rev, err := scanRevision(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Printf("WARN: Revision not found, ID: %s, task ID: %s", id, taskID)
+			return nil, notFound("revision", id)
+		}
+		log.Printf("ERROR: Database error in GetRevision for ID %s: %v", id, err)
+		return nil, err
+	}
+	return rev, nil
+}
+
+// scanRevision reads revision from a query result row
+func scanRevision(row interface{ Scan(dest ...any) error }) (*models.Revision, error) {
+	var rev models.Revision
+	var changes, snapshot string
+
+	err := row.Scan(&rev.ID, &rev.TaskID, &rev.Action, &rev.Actor, &changes, &snapshot, &rev.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(changes), &rev.Changes); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(snapshot), &rev.Snapshot); err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
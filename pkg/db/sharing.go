@@ -0,0 +1,231 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"strings"
+	models "todo/pkg/models"
+)
+
+// visibleTasks matches scheduler rows the :owner_id user may see: own, assigned and shared tasks
+const visibleTasks = `(owner_id = :owner_id OR assignee_id = :owner_id OR id IN (
+            SELECT task_id FROM task_shares WHERE user_id = :owner_id
+        ))`
+
+// editableTasks matches scheduler rows the :owner_id user may change
+const editableTasks = `(owner_id = :owner_id OR assignee_id = :owner_id OR id IN (
+            SELECT task_id FROM task_shares WHERE user_id = :owner_id AND permission = 'edit'
+        ))`
+
+// sharingColumns selects owner ID, owner and assignee logins of the scheduler row
+// and permission of the :owner_id user on it
+const sharingColumns = `owner_id, ifnull((SELECT login FROM users WHERE id = scheduler.owner_id), ''),
+        (SELECT login FROM users WHERE id = scheduler.assignee_id), CASE
+            WHEN owner_id = :owner_id THEN 'owner'
+            WHEN assignee_id = :owner_id THEN 'edit'
+            ELSE ifnull((SELECT permission FROM task_shares WHERE task_id = scheduler.id AND user_id = :owner_id), '')
+        END`
+
+// permissionLevels orders permissions, a higher level includes the lower ones
+var permissionLevels = map[string]int{
+	models.PermissionView:  1,
+	models.PermissionEdit:  2,
+	models.PermissionOwner: 3,
+}
+
+// getTaskWith reads task like getTask and checks that the context user has permission on it
+// Tasks the user sees with a lower permission are rejected with ErrForbidden
+func getTaskWith(ctx context.Context, q querier, id, permission string) (*models.Task, error) {
+	task, err := getTask(ctx, q, id)
+	if err != nil {
+		return nil, err
+	}
+	if permissionLevels[task.Permission] < permissionLevels[permission] {
+		log.Printf("WARN: Task %s requires %s permission, user %s has %s", id, permission, ownerID(ctx), task.Permission)
+		return nil, forbidden("task", id, permission)
+	}
+	return task, nil
+}
+
+// ownerScope returns ctx acting as the owner of the task
+// Tags, projects and blockers of a shared task are looked up among the owner's data
+func ownerScope(ctx context.Context, task *models.Task) context.Context {
+	if task.OwnerID == "" || task.OwnerID == ownerID(ctx) {
+		return ctx
+	}
+	return WithUser(ctx, &models.User{ID: task.OwnerID})
+}
+
+// userIDByLogin returns ID of the account with the login, field names the input in validation errors
+func userIDByLogin(ctx context.Context, q querier, login, field string) (string, string, error) {
+	var id, canonical string
+	err := q.QueryRowContext(ctx, `SELECT id, login FROM users WHERE login = :login`,
+		sql.Named("login", strings.TrimSpace(login))).Scan(&id, &canonical)
+	if err == sql.ErrNoRows {
+		log.Printf("WARN: User not found, login: %s", login)
+		return "", "", &ValidationError{Field: field, Message: "user " + login + " not found"}
+	}
+	if err != nil {
+		log.Printf("ERROR: Database error reading user %s: %v", login, err)
+		return "", "", err
+	}
+	return id, canonical, nil
+}
+
+// assigneeArg converts assignee login of a task to the assignee_id value
+// nil or empty login means no assignee, assignee is replaced with the stored login
+func assigneeArg(ctx context.Context, q querier, assignee *string) (any, error) {
+	if assignee == nil || *assignee == "" {
+		return nil, nil
+	}
+	id, login, err := userIDByLogin(ctx, q, *assignee, "assignee")
+	if err != nil {
+		return nil, err
+	}
+	*assignee = login
+	return id, nil
+}
+
+// GetShares retrieves users the task is shared with, sorted by login
+func (s *Storage) GetShares(ctx context.Context, taskID string) ([]*models.Share, error) {
+	log.Printf("DEBUG: Getting shares of task %s", taskID)
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	if _, err := getTask(ctx, s.db, taskID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+        SELECT u.id, u.login, ts.permission
+        FROM task_shares ts JOIN users u ON u.id = ts.user_id
+        WHERE ts.task_id = :task_id
+        ORDER BY u.login, u.id
+    `, sql.Named("task_id", taskID))
+	if err != nil {
+		log.Printf("ERROR: Database error in GetShares: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	shares := make([]*models.Share, 0)
+	for rows.Next() {
+		share := &models.Share{}
+		if err := rows.Scan(&share.UserID, &share.Login, &share.Permission); err != nil {
+			log.Printf("ERROR: Failed to scan share row: %v", err)
+			return nil, err
+		}
+		shares = append(shares, share)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Printf("ERROR: Row iteration error in GetShares: %v", err)
+		return nil, err
+	}
+	return shares, nil
+}
+
+// ShareTask grants the user access to the task, an existing share gets the new permission
+// Only the owner shares the task, share.UserID and share.Login are set to the stored account
+func (s *Storage) ShareTask(ctx context.Context, taskID string, share *models.Share) error {
+	log.Printf("DEBUG: Sharing task %s with %s, permission: %s", taskID, share.Login, share.Permission)
+
+	if share.Permission != models.PermissionView && share.Permission != models.PermissionEdit {
+		return &ValidationError{Field: "permission", Message: "permission must be view or edit"}
+	}
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("ERROR: Failed to begin transaction in ShareTask: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	task, err := getTaskWith(ctx, tx, taskID, models.PermissionOwner)
+	if err != nil {
+		return err
+	}
+
+	share.UserID, share.Login, err = userIDByLogin(ctx, tx, share.Login, "login")
+	if err != nil {
+		return err
+	}
+	if share.UserID == task.OwnerID {
+		return &ValidationError{Field: "login", Message: "task cannot be shared with its owner"}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        INSERT INTO task_shares (task_id, user_id, permission) VALUES (:task_id, :user_id, :permission)
+        ON CONFLICT (task_id, user_id) DO UPDATE SET permission = excluded.permission
+    `,
+		sql.Named("task_id", taskID),
+		sql.Named("user_id", share.UserID),
+		sql.Named("permission", share.Permission))
+	if err != nil {
+		log.Printf("ERROR: Database error in ShareTask: %v", err)
+		return mapError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("ERROR: Failed to commit ShareTask: %v", err)
+		return err
+	}
+
+	log.Printf("INFO: Task %s shared with %s, permission: %s", taskID, share.Login, share.Permission)
+	return nil
+}
+
+// UnshareTask revokes access of the user to the task
+// The owner revokes any share, other users may only leave a task shared with them
+func (s *Storage) UnshareTask(ctx context.Context, taskID, login string) error {
+	log.Printf("DEBUG: Unsharing task %s with %s", taskID, login)
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("ERROR: Failed to begin transaction in UnshareTask: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	task, err := getTask(ctx, tx, taskID)
+	if err != nil {
+		return err
+	}
+	userID, _, err := userIDByLogin(ctx, tx, login, "login")
+	if err != nil {
+		return err
+	}
+	if task.Permission != models.PermissionOwner && userID != ownerID(ctx) {
+		log.Printf("WARN: User %s may not revoke share of task %s for %s", ownerID(ctx), taskID, login)
+		return forbidden("task", taskID, models.PermissionOwner)
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM task_shares WHERE task_id = :task_id AND user_id = :user_id`,
+		sql.Named("task_id", taskID), sql.Named("user_id", userID))
+	if err != nil {
+		log.Printf("ERROR: Database error in UnshareTask: %v", err)
+		return err
+	}
+	if count, err := result.RowsAffected(); err != nil {
+		return err
+	} else if count == 0 {
+		log.Printf("WARN: Task %s is not shared with %s", taskID, login)
+		return notFound("share", login)
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("ERROR: Failed to commit UnshareTask: %v", err)
+		return err
+	}
+
+	log.Printf("INFO: Share of task %s for %s revoked", taskID, login)
+	return nil
+}
@@ -0,0 +1,98 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+	models "todo/pkg/models"
+)
+
+// SnoozeDateFunc calculates the date a task due on date is snoozed to, date is empty for undated tasks
+type SnoozeDateFunc func(now time.Time, date, to string) (string, error)
+
+// SnoozeTask moves task to another date in a single transaction
+// Start date is shifted by the same number of days and never ends up after the new date.
+// Recurring task keeps the date of the snoozed occurrence, so the series is not moved,
+// "snooze" revision is recorded in the same transaction
+// id - task identifier
+// to - relative offset or date, see models.SnoozeInput
+// actor - who snoozed the task, stored in the revision
+// snooze - calculates the new date
+// Returns task state before and after snoozing
+func (s *Storage) SnoozeTask(ctx context.Context, id, to, actor string, snooze SnoozeDateFunc) (*models.Task, *models.Task, error) {
+	log.Printf("DEBUG: Snoozing task, ID: %s, to: %s", id, to)
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("ERROR: Failed to begin transaction in SnoozeTask: %v", err)
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	before, err := getTaskWith(ctx, tx, id, models.PermissionEdit)
+	if err != nil {
+		return nil, nil, err
+	}
+	if before.CompletedAt != "" {
+		log.Printf("WARN: Task %s is already done, nothing to snooze", id)
+		return nil, nil, fmt.Errorf("%w: task %s is already done", ErrConflict, id)
+	}
+
+	after := *before
+	after.Date, err = snooze(time.Now(), before.Date, to)
+	if err != nil {
+		log.Printf("WARN: Snooze date calculation failed for task %s: %v", id, err)
+		return nil, nil, &ValidationError{Field: "to", Message: err.Error()}
+	}
+	if before.Date != "" {
+		after.Start, err = ShiftStart(before.Start, before.Date, after.Date)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if after.Start > after.Date {
+		after.Start = after.Date
+	}
+
+	if before.Repeat != "" && before.SnoozedFrom == "" {
+		after.SnoozedFrom = before.Date
+	}
+	if after.SnoozedFrom == after.Date {
+		// Snoozed back to the occurrence itself
+		after.SnoozedFrom = ""
+	}
+
+	err = tx.QueryRowContext(ctx, `
+        UPDATE scheduler
+        SET date = :date,
+            start = :start,
+            snoozed_from = :snoozed_from,
+            version = version + 1
+        WHERE id = :id
+        RETURNING version
+    `,
+		sql.Named("date", after.Date),
+		sql.Named("start", after.Start),
+		sql.Named("snoozed_from", after.SnoozedFrom),
+		sql.Named("id", id)).Scan(&after.Version)
+	if err != nil {
+		log.Printf("ERROR: Database error in SnoozeTask for ID %s: %v", id, err)
+		return nil, nil, mapError(err)
+	}
+	if _, err := addRevision(ctx, tx, id, models.ActionSnooze, actor, before, &after); err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("ERROR: Failed to commit SnoozeTask for ID %s: %v", id, err)
+		return nil, nil, err
+	}
+
+	log.Printf("INFO: Task snoozed, ID: %s, date: %s -> %s", id, before.Date, after.Date)
+	return before, &after, nil
+}
@@ -0,0 +1,325 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	models "todo/pkg/models"
+)
+
+// maxTagLength - longest accepted tag name
+const maxTagLength = 64
+
+// tagsColumn selects comma separated tags of the scheduler row sorted by name
+const tagsColumn = `(
+            SELECT coalesce(group_concat(t.name, ',' ORDER BY t.name), '')
+            FROM task_tags tt JOIN tags t ON t.id = tt.tag_id
+            WHERE tt.task_id = scheduler.id
+        )`
+
+// tagFilter matches scheduler rows having tag with key :tag
+const tagFilter = `id IN (
+            SELECT tt.task_id FROM task_tags tt JOIN tags t ON t.id = tt.tag_id
+            WHERE t.key = :tag
+        )`
+
+// splitTags parses value of tagsColumn
+func splitTags(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+// tagKey returns case-insensitive key of a normalized tag name
+func tagKey(name string) string {
+	return strings.ToLower(name)
+}
+
+// normalizeTag trims tag name and strips leading '#'
+func normalizeTag(name string) (string, error) {
+	name = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(name), "#"))
+	switch {
+	case name == "":
+		return "", &ValidationError{Field: "tags", Message: "tag name is empty"}
+	case len([]rune(name)) > maxTagLength:
+		return "", &ValidationError{Field: "tags", Message: "tag name is too long: " + name}
+	case strings.ContainsAny(name, ",#"):
+		return "", &ValidationError{Field: "tags", Message: "tag name must not contain ',' or '#': " + name}
+	}
+	return name, nil
+}
+
+// setTaskTags replaces tags of the task using q, which must be a transaction
+// task.Tags is replaced with the stored names
+func setTaskTags(ctx context.Context, q querier, task *models.Task) error {
+	names := make([]string, 0, len(task.Tags))
+	for _, tag := range task.Tags {
+		name, err := normalizeTag(tag)
+		if err != nil {
+			return err
+		}
+		names = append(names, name)
+	}
+
+	_, err := q.ExecContext(ctx, `DELETE FROM task_tags WHERE task_id = :task_id`, sql.Named("task_id", task.ID))
+	if err != nil {
+		log.Printf("ERROR: Failed to clear tags of task %s: %v", task.ID, err)
+		return err
+	}
+
+	for _, name := range names {
+		_, err := q.ExecContext(ctx, `
+            INSERT INTO tags (owner_id, name, key) VALUES (:owner_id, :name, :key)
+            ON CONFLICT (owner_id, key) DO NOTHING
+        `, sql.Named("owner_id", ownerID(ctx)), sql.Named("name", name), sql.Named("key", tagKey(name)))
+		if err != nil {
+			log.Printf("ERROR: Failed to create tag %s: %v", name, err)
+			return mapError(err)
+		}
+
+		_, err = q.ExecContext(ctx, `
+            INSERT OR IGNORE INTO task_tags (task_id, tag_id)
+            SELECT :task_id, id FROM tags WHERE owner_id = :owner_id AND key = :key
+        `,
+			sql.Named("task_id", task.ID),
+			sql.Named("owner_id", ownerID(ctx)),
+			sql.Named("key", tagKey(name)))
+		if err != nil {
+			log.Printf("ERROR: Failed to tag task %s with %s: %v", task.ID, name, err)
+			return mapError(err)
+		}
+	}
+
+	if err := deleteOrphanTags(ctx, q); err != nil {
+		return err
+	}
+
+	task.Tags, err = taskTags(ctx, q, task.ID)
+	return err
+}
+
+// taskTags returns tags of the task sorted by name
+func taskTags(ctx context.Context, q querier, taskID string) ([]string, error) {
+	var tags string
+	err := q.QueryRowContext(ctx, `SELECT `+tagsColumn+` FROM scheduler WHERE id = :id`,
+		sql.Named("id", taskID)).Scan(&tags)
+	if err != nil {
+		log.Printf("ERROR: Failed to read tags of task %s: %v", taskID, err)
+		return nil, err
+	}
+	return splitTags(tags), nil
+}
+
+// deleteOrphanTags removes tags no task refers to
+func deleteOrphanTags(ctx context.Context, q querier) error {
+	_, err := q.ExecContext(ctx, `DELETE FROM tags WHERE id NOT IN (SELECT tag_id FROM task_tags)`)
+	if err != nil {
+		log.Printf("ERROR: Failed to delete unused tags: %v", err)
+	}
+	return err
+}
+
+// GetTags retrieves tags of active tasks of the context user with task counts, sorted by name
+func (s *Storage) GetTags(ctx context.Context) ([]models.Tag, error) {
+	log.Printf("DEBUG: Getting tags")
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+        SELECT t.name, count(*)
+        FROM tags t
+        JOIN task_tags tt ON tt.tag_id = t.id
+        JOIN scheduler s ON s.id = tt.task_id AND s.deleted_at = '' AND s.completed_at = ''
+        WHERE t.owner_id = :owner_id
+        GROUP BY t.id
+        ORDER BY t.name
+    `, sql.Named("owner_id", ownerID(ctx)))
+	if err != nil {
+		log.Printf("ERROR: Database error in GetTags: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := make([]models.Tag, 0)
+	for rows.Next() {
+		var tag models.Tag
+		if err := rows.Scan(&tag.Name, &tag.Tasks); err != nil {
+			log.Printf("ERROR: Failed to scan tag row: %v", err)
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Printf("ERROR: Row iteration error in GetTags: %v", err)
+		return nil, err
+	}
+	log.Printf("DEBUG: Retrieved %d tags", len(tags))
+	return tags, nil
+}
+
+// RenameTag changes tag name on all tasks
+// Returns ErrConflict when another tag already has the new name, merge tags instead
+func (s *Storage) RenameTag(ctx context.Context, from, to string) error {
+	log.Printf("DEBUG: Renaming tag %s to %s", from, to)
+
+	to, err := normalizeTag(to)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("ERROR: Failed to begin transaction in RenameTag: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	id, err := tagID(ctx, tx, from)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE tags SET name = :name, key = :key WHERE id = :id`,
+		sql.Named("name", to),
+		sql.Named("key", tagKey(to)),
+		sql.Named("id", id))
+	if err != nil {
+		log.Printf("ERROR: Database error in RenameTag: %v", err)
+		return mapError(err)
+	}
+
+	if err := touchTaggedTasks(ctx, tx, id); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("ERROR: Failed to commit RenameTag: %v", err)
+		return err
+	}
+
+	log.Printf("INFO: Tag %s renamed to %s", from, to)
+	return nil
+}
+
+// MergeTags moves tasks of the from tags to the to tag and removes the from tags
+// The to tag is created when it does not exist
+func (s *Storage) MergeTags(ctx context.Context, from []string, to string) error {
+	log.Printf("DEBUG: Merging tags %v into %s", from, to)
+
+	to, err := normalizeTag(to)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("ERROR: Failed to begin transaction in MergeTags: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+        INSERT INTO tags (owner_id, name, key) VALUES (:owner_id, :name, :key)
+        ON CONFLICT (owner_id, key) DO NOTHING
+    `, sql.Named("owner_id", ownerID(ctx)), sql.Named("name", to), sql.Named("key", tagKey(to)))
+	if err != nil {
+		log.Printf("ERROR: Failed to create tag %s: %v", to, err)
+		return mapError(err)
+	}
+	toID, err := tagID(ctx, tx, to)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range from {
+		fromID, err := tagID(ctx, tx, name)
+		if err != nil {
+			return err
+		}
+		if fromID == toID {
+			continue
+		}
+
+		if err := touchTaggedTasks(ctx, tx, fromID); err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `
+            INSERT OR IGNORE INTO task_tags (task_id, tag_id)
+            SELECT task_id, :to_id FROM task_tags WHERE tag_id = :from_id
+        `,
+			sql.Named("to_id", toID),
+			sql.Named("from_id", fromID))
+		if err != nil {
+			log.Printf("ERROR: Failed to move tasks of tag %s: %v", name, err)
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `DELETE FROM task_tags WHERE tag_id = :id`, sql.Named("id", fromID))
+		if err != nil {
+			log.Printf("ERROR: Failed to untag tasks of tag %s: %v", name, err)
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `DELETE FROM tags WHERE id = :id`, sql.Named("id", fromID))
+		if err != nil {
+			log.Printf("ERROR: Failed to delete tag %s: %v", name, err)
+			return err
+		}
+	}
+
+	if err := deleteOrphanTags(ctx, tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("ERROR: Failed to commit MergeTags: %v", err)
+		return err
+	}
+
+	log.Printf("INFO: Tags %v merged into %s", from, to)
+	return nil
+}
+
+// tagID finds tag of the context user by name, case-insensitively
+func tagID(ctx context.Context, q querier, name string) (int64, error) {
+	name, err := normalizeTag(name)
+	if err != nil {
+		return 0, err
+	}
+
+	var id int64
+	err = q.QueryRowContext(ctx, `SELECT id FROM tags WHERE owner_id = :owner_id AND key = :key`,
+		sql.Named("owner_id", ownerID(ctx)),
+		sql.Named("key", tagKey(name))).Scan(&id)
+	if err == sql.ErrNoRows {
+		log.Printf("WARN: Tag not found: %s", name)
+		return 0, fmt.Errorf("tag %s %w", name, ErrNotFound)
+	}
+	if err != nil {
+		log.Printf("ERROR: Database error reading tag %s: %v", name, err)
+		return 0, err
+	}
+	return id, nil
+}
+
+// touchTaggedTasks bumps version of tasks with the tag, their tag list is about to change
+func touchTaggedTasks(ctx context.Context, q querier, tagID int64) error {
+	_, err := q.ExecContext(ctx, `
+        UPDATE scheduler
+        SET version = version + 1
+        WHERE id IN (SELECT task_id FROM task_tags WHERE tag_id = :tag_id)
+    `, sql.Named("tag_id", tagID))
+	if err != nil {
+		log.Printf("ERROR: Failed to update versions of tagged tasks: %v", err)
+	}
+	return err
+}
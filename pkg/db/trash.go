@@ -0,0 +1,228 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"strings"
+	"time"
+	models "todo/pkg/models"
+)
+
+// GetTrash retrieves tasks from the trash, most recently deleted first
+// limit - maximum number of tasks to return
+func (s *Storage) GetTrash(ctx context.Context, limit int) (TasksResp, error) {
+	log.Printf("DEBUG: Getting trash, limit: %d", limit)
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+        SELECT `+taskColumns+`
+        FROM scheduler
+        WHERE deleted_at != '' AND owner_id = :owner_id
+        ORDER BY deleted_at DESC, id DESC
+        LIMIT :limit
+    `, sql.Named("limit", limit), sql.Named("owner_id", ownerID(ctx)))
+	if err != nil {
+		log.Printf("ERROR: Database error in GetTrash: %v", err)
+		return TasksResp{}, err
+	}
+	defer rows.Close()
+
+	var resp TasksResp
+	resp.Tasks = make([]*models.Task, 0)
+
+	for rows.Next() {
+		t, err := scanTask(rows)
+		if err != nil {
+			log.Printf("ERROR: Failed to scan task row in GetTrash: %v", err)
+			return TasksResp{}, err
+		}
+		resp.Tasks = append(resp.Tasks, t)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Printf("ERROR: Row iteration error in GetTrash: %v", err)
+		return TasksResp{}, err
+	}
+	log.Printf("DEBUG: Retrieved %d tasks from trash", len(resp.Tasks))
+	return resp, nil
+}
+
+// RestoreTask moves task from the trash back to the scheduler, "restore" revision is recorded in the same transaction
+// id - task identifier
+// actor - who restored the task, stored in the revision
+func (s *Storage) RestoreTask(ctx context.Context, id, actor string) error {
+	log.Printf("DEBUG: Restoring task from trash, ID: %s", id)
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("ERROR: Failed to begin transaction in RestoreTask: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	var deletedAt string
+	err = tx.QueryRowContext(ctx, `
+        SELECT deleted_at FROM scheduler
+        WHERE id = :id AND deleted_at != '' AND owner_id = :owner_id
+    `,
+		sql.Named("id", id),
+		sql.Named("owner_id", ownerID(ctx))).Scan(&deletedAt)
+	if err == sql.ErrNoRows {
+		log.Printf("WARN: Task not found in trash, ID: %s", id)
+		return notFound("trashed task", id)
+	}
+	if err != nil {
+		log.Printf("ERROR: Database error in RestoreTask for ID %s: %v", id, err)
+		return err
+	}
+
+	after, err := scanTask(tx.QueryRowContext(ctx, `
+        UPDATE scheduler
+        SET deleted_at = '',
+            version = version + 1
+        WHERE id = :id
+        RETURNING `+taskColumns+`
+    `,
+		sql.Named("id", id),
+		sql.Named("owner_id", ownerID(ctx))))
+	if err != nil {
+		log.Printf("ERROR: Database error in RestoreTask for ID %s: %v", id, err)
+		return err
+	}
+
+	before := *after
+	before.DeletedAt = deletedAt
+	if _, err := addRevision(ctx, tx, id, models.ActionRestore, actor, &before, after); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("ERROR: Failed to commit RestoreTask for ID %s: %v", id, err)
+		return err
+	}
+
+	log.Printf("INFO: Task restored from trash, ID: %s", id)
+	return nil
+}
+
+// taskRows deletes rows referring to the tasks selected by {tasks}
+// They are deleted explicitly, so purged tasks leave no orphans with TODO_DB_FOREIGN_KEYS=false
+// and a reused task ID does not inherit them. Revisions and completions outlive purged tasks
+var taskRows = []string{
+	`DELETE FROM task_tags WHERE task_id IN ({tasks})`,
+	`DELETE FROM checklist_items WHERE task_id IN ({tasks})`,
+	`DELETE FROM attachments WHERE task_id IN ({tasks})`,
+	`DELETE FROM task_dependencies WHERE task_id IN ({tasks}) OR blocker_id IN ({tasks})`,
+	`DELETE FROM task_shares WHERE task_id IN ({tasks})`,
+}
+
+// purgeTasks permanently removes tasks selected by the tasks query together with their rows
+// using q, which must be a transaction. Tags left without tasks are removed too
+// Returns number of purged tasks
+func purgeTasks(ctx context.Context, q querier, tasks string, args ...any) (int64, error) {
+	for _, stmt := range taskRows {
+		if _, err := q.ExecContext(ctx, strings.ReplaceAll(stmt, "{tasks}", tasks), args...); err != nil {
+			log.Printf("ERROR: Failed to delete rows of purged tasks: %v", err)
+			return 0, err
+		}
+	}
+
+	result, err := q.ExecContext(ctx, `DELETE FROM scheduler WHERE id IN (`+tasks+`)`, args...)
+	if err != nil {
+		log.Printf("ERROR: Failed to purge tasks: %v", err)
+		return 0, err
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("ERROR: Failed to get rows affected in purgeTasks: %v", err)
+		return 0, err
+	}
+	if count > 0 {
+		if err := deleteOrphanTags(ctx, q); err != nil {
+			return 0, err
+		}
+	}
+	return count, nil
+}
+
+// PurgeTask permanently removes single task from the trash
+// id - task identifier
+func (s *Storage) PurgeTask(ctx context.Context, id string) error {
+	log.Printf("DEBUG: Purging task from trash, ID: %s", id)
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("ERROR: Failed to begin transaction in PurgeTask: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	count, err := purgeTasks(ctx, tx, `
+        SELECT id FROM scheduler
+        WHERE id = :id AND deleted_at != '' AND owner_id = :owner_id
+    `,
+		sql.Named("id", id),
+		sql.Named("owner_id", ownerID(ctx)))
+	if err != nil {
+		log.Printf("ERROR: Database error in PurgeTask for ID %s: %v", id, err)
+		return err
+	}
+	if count == 0 {
+		log.Printf("WARN: Task not found in trash, ID: %s", id)
+		return notFound("trashed task", id)
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("ERROR: Failed to commit PurgeTask for ID %s: %v", id, err)
+		return err
+	}
+
+	log.Printf("INFO: Task purged from trash, ID: %s", id)
+	return nil
+}
+
+// PurgeTrash permanently removes tasks deleted at or before the given moment
+// Only tasks of the context user are purged, a context without user purges tasks of all users
+// Returns number of purged tasks
+func (s *Storage) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	log.Printf("DEBUG: Purging trash, deleted before: %s", before.UTC().Format(time.RFC3339))
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("ERROR: Failed to begin transaction in PurgeTrash: %v", err)
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	count, err := purgeTasks(ctx, tx, `
+        SELECT id FROM scheduler
+        WHERE deleted_at != '' AND deleted_at <= :before
+            AND (:owner_id = '' OR owner_id = :owner_id)
+    `,
+		sql.Named("before", before.UTC().Format(time.RFC3339)),
+		sql.Named("owner_id", ownerID(ctx)))
+	if err != nil {
+		log.Printf("ERROR: Database error in PurgeTrash: %v", err)
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("ERROR: Failed to commit PurgeTrash: %v", err)
+		return 0, err
+	}
+
+	log.Printf("INFO: Purged %d tasks from trash", count)
+	return count, nil
+}
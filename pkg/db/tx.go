@@ -0,0 +1,94 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	models "todo/pkg/models"
+)
+
+// Tx is a storage transaction, changes made through it are committed together
+// Methods mirror Storage methods of the same name
+type Tx struct {
+	tx         *sql.Tx
+	savepoints int
+}
+
+// Batch runs fn in a single transaction
+// The transaction is committed when fn returns nil and rolled back otherwise,
+// the query timeout applies to the whole batch
+func (s *Storage) Batch(ctx context.Context, fn func(ctx context.Context, tx *Tx) error) error {
+	log.Printf("DEBUG: Starting batch transaction")
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("ERROR: Failed to begin batch transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(ctx, &Tx{tx: tx}); err != nil {
+		log.Printf("WARN: Batch transaction rolled back: %v", err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("ERROR: Failed to commit batch transaction: %v", err)
+		return err
+	}
+
+	log.Printf("INFO: Batch transaction committed")
+	return nil
+}
+
+// Savepoint runs fn inside a savepoint
+// When fn fails its changes are undone and the transaction stays usable
+func (t *Tx) Savepoint(ctx context.Context, fn func() error) error {
+	t.savepoints++
+	name := fmt.Sprintf("batch_item_%d", t.savepoints)
+
+	if _, err := t.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return err
+	}
+
+	if err := fn(); err != nil {
+		if _, rerr := t.tx.ExecContext(ctx, "ROLLBACK TO "+name); rerr != nil {
+			log.Printf("ERROR: Failed to roll back to savepoint %s: %v", name, rerr)
+			return rerr
+		}
+		t.tx.ExecContext(ctx, "RELEASE "+name)
+		return err
+	}
+
+	_, err := t.tx.ExecContext(ctx, "RELEASE "+name)
+	return err
+}
+
+// AddTask creates a new task within the transaction
+func (t *Tx) AddTask(ctx context.Context, task *models.Task, actor string) (int64, error) {
+	return addTask(ctx, t.tx, task, actor)
+}
+
+// GetTask retrieves single task by ID within the transaction
+func (t *Tx) GetTask(ctx context.Context, id string) (*models.Task, error) {
+	return getTask(ctx, t.tx, id)
+}
+
+// UpdateTask updates existing task within the transaction, see Storage.UpdateTask
+func (t *Tx) UpdateTask(ctx context.Context, task *models.Task, action, actor string) (*models.Task, error) {
+	return updateTask(ctx, t.tx, task, action, actor)
+}
+
+// DeleteTask moves task to the trash within the transaction, see Storage.DeleteTask
+func (t *Tx) DeleteTask(ctx context.Context, id, actor string) (*models.Task, error) {
+	return deleteTask(ctx, t.tx, id, actor)
+}
+
+// CompleteTask marks task as done within the transaction, see Storage.CompleteTask
+func (t *Tx) CompleteTask(ctx context.Context, id, note, actor string, next NextDateFunc) (*models.Task, *models.Task, error) {
+	return completeTask(ctx, t.tx, id, note, actor, next)
+}
@@ -0,0 +1,305 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	models "todo/pkg/models"
+)
+
+// AdminID - ID of the account created with the database, it owns data created before accounts existed
+const AdminID = "1"
+
+// userLogin matches accepted logins
+var userLogin = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// userKey is the context key of the authenticated user
+type userKey struct{}
+
+// WithUser returns ctx carrying the authenticated user
+// Storage methods called with the context see and change data of that user only
+func WithUser(ctx context.Context, user *models.User) context.Context {
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+// UserFrom returns the user stored in ctx by WithUser, nil if there is none
+func UserFrom(ctx context.Context) *models.User {
+	user, _ := ctx.Value(userKey{}).(*models.User)
+	return user
+}
+
+// ownerID returns ID of the user the data is scoped to
+// Empty ID of a context without user matches no rows
+func ownerID(ctx context.Context) string {
+	if user := UserFrom(ctx); user != nil {
+		return user.ID
+	}
+	return ""
+}
+
+// userColumns lists users columns read by scanUser
+const userColumns = `id, login, password_hash, role, created_at`
+
+// scanUser reads user row selected with userColumns
+func scanUser(row rowScanner) (*models.User, error) {
+	var u models.User
+	var id int64
+	if err := row.Scan(&id, &u.Login, &u.PasswordHash, &u.Role, &u.CreatedAt); err != nil {
+		return nil, err
+	}
+	u.ID = strconv.FormatInt(id, 10)
+	return &u, nil
+}
+
+// GetUsers retrieves all accounts sorted by login
+func (s *Storage) GetUsers(ctx context.Context) ([]*models.User, error) {
+	log.Printf("DEBUG: Getting users")
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `SELECT `+userColumns+` FROM users ORDER BY login, id`)
+	if err != nil {
+		log.Printf("ERROR: Database error in GetUsers: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make([]*models.User, 0)
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			log.Printf("ERROR: Failed to scan user row: %v", err)
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	if err = rows.Err(); err != nil {
+		log.Printf("ERROR: Row iteration error in GetUsers: %v", err)
+		return nil, err
+	}
+	return users, nil
+}
+
+// HasUsers reports whether accounts other than the admin created with the database exist
+func (s *Storage) HasUsers(ctx context.Context) (bool, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	var exists bool
+	err := s.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE id <> :id)`,
+		sql.Named("id", AdminID)).Scan(&exists)
+	if err != nil {
+		log.Printf("ERROR: Database error in HasUsers: %v", err)
+	}
+	return exists, err
+}
+
+// GetUser retrieves account by ID
+func (s *Storage) GetUser(ctx context.Context, id string) (*models.User, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	u, err := scanUser(s.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE id = :id`, sql.Named("id", id)))
+	if err == sql.ErrNoRows {
+		log.Printf("WARN: User not found, ID: %s", id)
+		return nil, notFound("user", id)
+	}
+	if err != nil {
+		log.Printf("ERROR: Database error reading user %s: %v", id, err)
+		return nil, err
+	}
+	return u, nil
+}
+
+// GetUserByLogin retrieves account by login, case-insensitive
+func (s *Storage) GetUserByLogin(ctx context.Context, login string) (*models.User, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	u, err := scanUser(s.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE login = :login`,
+		sql.Named("login", strings.TrimSpace(login))))
+	if err == sql.ErrNoRows {
+		log.Printf("WARN: User not found, login: %s", login)
+		return nil, notFound("user", login)
+	}
+	if err != nil {
+		log.Printf("ERROR: Database error reading user %s: %v", login, err)
+		return nil, err
+	}
+	return u, nil
+}
+
+// validateRole checks that role is one of the known roles
+func validateRole(role string) error {
+	switch role {
+	case models.RoleAdmin, models.RoleEditor, models.RoleReadOnly:
+		return nil
+	}
+	return &ValidationError{Field: "role", Message: "role must be one of admin, editor, readonly"}
+}
+
+// AddUser creates a new account, user.PasswordHash must be set
+// Empty role makes an editor, returns ErrConflict when the login is taken
+func (s *Storage) AddUser(ctx context.Context, user *models.User) (int64, error) {
+	log.Printf("DEBUG: Adding user: %s", user.Login)
+
+	user.Login = strings.TrimSpace(user.Login)
+	if !userLogin.MatchString(user.Login) {
+		return 0, &ValidationError{Field: "login", Message: "login must be 1 to 64 latin letters, digits, '.', '_' or '-'"}
+	}
+	if user.Role == "" {
+		user.Role = models.RoleEditor
+	}
+	if err := validateRole(user.Role); err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	user.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+	result, err := s.db.ExecContext(ctx, `
+        INSERT INTO users (login, password_hash, role, created_at)
+        VALUES (:login, :password_hash, :role, :created_at)
+    `,
+		sql.Named("login", user.Login),
+		sql.Named("password_hash", user.PasswordHash),
+		sql.Named("role", user.Role),
+		sql.Named("created_at", user.CreatedAt))
+	if err != nil {
+		log.Printf("ERROR: Database error in AddUser: %v", err)
+		return 0, mapError(err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		log.Printf("ERROR: Failed to get last insert ID: %v", err)
+		return 0, err
+	}
+	user.ID = strconv.FormatInt(id, 10)
+
+	log.Printf("INFO: User created, ID: %d, login: %s", id, user.Login)
+	return id, nil
+}
+
+// SetPassword replaces password hash of the account
+func (s *Storage) SetPassword(ctx context.Context, id, hash string) error {
+	log.Printf("DEBUG: Setting password of user %s", id)
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, `UPDATE users SET password_hash = :hash WHERE id = :id`,
+		sql.Named("hash", hash), sql.Named("id", id))
+	if err != nil {
+		log.Printf("ERROR: Database error in SetPassword for user %s: %v", id, err)
+		return err
+	}
+	if count, err := result.RowsAffected(); err != nil {
+		return err
+	} else if count == 0 {
+		log.Printf("WARN: User not found for password change, ID: %s", id)
+		return notFound("user", id)
+	}
+
+	log.Printf("INFO: Password of user %s changed", id)
+	return nil
+}
+
+// SetRole changes role of the account
+// The admin account created with the database stays admin
+func (s *Storage) SetRole(ctx context.Context, id, role string) error {
+	log.Printf("DEBUG: Setting role of user %s to %s", id, role)
+
+	if err := validateRole(role); err != nil {
+		return err
+	}
+	if id == AdminID && role != models.RoleAdmin {
+		return &ValidationError{Field: "role", Message: "the initial admin account must stay admin"}
+	}
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, `UPDATE users SET role = :role WHERE id = :id`,
+		sql.Named("role", role), sql.Named("id", id))
+	if err != nil {
+		log.Printf("ERROR: Database error in SetRole for user %s: %v", id, err)
+		return mapError(err)
+	}
+	if count, err := result.RowsAffected(); err != nil {
+		return err
+	} else if count == 0 {
+		log.Printf("WARN: User not found for role change, ID: %s", id)
+		return notFound("user", id)
+	}
+
+	log.Printf("INFO: Role of user %s changed to %s", id, role)
+	return nil
+}
+
+// userRows deletes rows of the :id user besides tasks, explicitly like taskRows
+var userRows = []string{
+	`DELETE FROM task_shares WHERE user_id = :id`,
+	`UPDATE scheduler SET assignee_id = NULL WHERE assignee_id = :id`,
+	`DELETE FROM completions WHERE owner_id = :id`,
+	`DELETE FROM revisions WHERE owner_id = :id`,
+	`DELETE FROM tags WHERE owner_id = :id`,
+	`DELETE FROM projects WHERE owner_id = :id`,
+}
+
+// DeleteUser removes the account together with its tasks, projects and tags
+// The admin account created with the database cannot be deleted
+func (s *Storage) DeleteUser(ctx context.Context, id string) error {
+	log.Printf("DEBUG: Deleting user, ID: %s", id)
+
+	if id == AdminID {
+		return &ValidationError{Field: "id", Message: "the initial admin account cannot be deleted"}
+	}
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("ERROR: Failed to begin transaction in DeleteUser: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := purgeTasks(ctx, tx, `SELECT id FROM scheduler WHERE owner_id = :id`, sql.Named("id", id)); err != nil {
+		log.Printf("ERROR: Database error deleting tasks of user %s: %v", id, err)
+		return err
+	}
+	for _, stmt := range userRows {
+		if _, err := tx.ExecContext(ctx, stmt, sql.Named("id", id)); err != nil {
+			log.Printf("ERROR: Database error deleting data of user %s: %v", id, err)
+			return err
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM users WHERE id = :id`, sql.Named("id", id))
+	if err != nil {
+		log.Printf("ERROR: Database error in DeleteUser for ID %s: %v", id, err)
+		return err
+	}
+	if count, err := result.RowsAffected(); err != nil {
+		return err
+	} else if count == 0 {
+		log.Printf("WARN: User not found for deletion, ID: %s", id)
+		return notFound("user", id)
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("ERROR: Failed to commit DeleteUser for ID %s: %v", id, err)
+		return err
+	}
+
+	log.Printf("INFO: User deleted, ID: %s", id)
+	return nil
+}
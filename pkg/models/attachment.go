@@ -0,0 +1,12 @@
+package models
+
+// Attachment describes a file attached to a task
+type Attachment struct {
+	ID          string `json:"id"`
+	TaskID      string `json:"task_id"`
+	Name        string `json:"name"`
+	ContentType string `json:"content_type,omitempty"`
+	Size        int64  `json:"size"`
+	SHA256      string `json:"sha256"`
+	CreatedAt   string `json:"created_at"` // RFC3339
+}
@@ -5,7 +5,9 @@ import (
 )
 
 // SignInInput - the structure for entry
+// Login may be omitted to sign in as the admin account
 type SignInInput struct {
+	Login    string `json:"login"`
 	Password string `json:"password"`
 }
 
@@ -15,7 +17,10 @@ type SignInResponse struct {
 }
 
 // Claims - the structure for the JWT token
+// PasswordHash is a fingerprint of the stored password, tokens stop working when the password or role changes
 type Claims struct {
+	UserID       string `json:"uid"`
+	Role         string `json:"role"`
 	PasswordHash string `json:"pwd_hash"`
 	jwt.RegisteredClaims
 }
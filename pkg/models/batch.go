@@ -0,0 +1,39 @@
+package models
+
+// Batch operation kinds
+const (
+	BatchCreate = "create"
+	BatchUpdate = "update"
+	BatchDone   = "done"
+	BatchDelete = "delete"
+)
+
+// Batch modes
+const (
+	BatchAtomic  = "atomic"   // all operations are applied or none
+	BatchPerItem = "per_item" // failed operations are skipped, the rest is applied
+)
+
+// BatchInput is the body of POST /api/tasks/batch
+type BatchInput struct {
+	Mode       string           `json:"mode,omitempty"` // BatchAtomic by default
+	Operations []BatchOperation `json:"operations"`
+}
+
+// BatchOperation is a single operation of a batch
+// Task is used by create and update, ID and Note by done and delete
+type BatchOperation struct {
+	Op   string `json:"op"`
+	Task *Task  `json:"task,omitempty"`
+	ID   string `json:"id,omitempty"`
+	Note string `json:"note,omitempty"`
+}
+
+// BatchResult reports outcome of a single batch operation
+type BatchResult struct {
+	Index  int    `json:"index"`
+	Op     string `json:"op"`
+	ID     string `json:"id,omitempty"`
+	Status int    `json:"status"` // HTTP status the operation would get as a single call
+	Error  string `json:"error,omitempty"`
+}
@@ -0,0 +1,21 @@
+package models
+
+// ChecklistItem is a step of a task checklist
+type ChecklistItem struct {
+	ID       string `json:"id"`
+	TaskID   string `json:"task_id"`
+	Title    string `json:"title"`
+	Done     bool   `json:"done"`
+	Position int    `json:"position"` // items are ordered by position, starting at 1
+}
+
+// Progress counts done checklist items of a task
+type Progress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+
+// ChecklistReorderInput is the body of POST /api/checklist/reorder
+type ChecklistReorderInput struct {
+	Items []string `json:"items"` // IDs of all items of the task in the new order
+}
@@ -0,0 +1,16 @@
+package models
+
+// Completion is a record of a task being marked as done
+type Completion struct {
+	ID          string `json:"id"`
+	TaskID      string `json:"task_id"`
+	Title       string `json:"title"`
+	Date        string `json:"date"`         // scheduled date in YYYYMMDD format
+	CompletedAt string `json:"completed_at"` // RFC3339
+	Note        string `json:"note,omitempty"`
+}
+
+// DoneInput - optional body of the "done" request
+type DoneInput struct {
+	Note string `json:"note"`
+}
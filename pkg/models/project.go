@@ -0,0 +1,10 @@
+package models
+
+// Project is a separate list of tasks, e.g. home or work backlog
+type Project struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Color    string `json:"color,omitempty"` // #rrggbb
+	Archived bool   `json:"archived"`        // archived projects accept no new tasks
+	Tasks    int    `json:"tasks"`           // number of active tasks, read only
+}
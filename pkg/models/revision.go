@@ -0,0 +1,30 @@
+package models
+
+// Revision actions
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDone    = "done"
+	ActionDelete  = "delete"
+	ActionRevert  = "revert"
+	ActionReopen  = "reopen"
+	ActionSnooze  = "snooze"
+	ActionRestore = "restore"
+)
+
+// Revision is a single recorded change of a task
+type Revision struct {
+	ID        string                 `json:"id"`
+	TaskID    string                 `json:"task_id"`
+	Action    string                 `json:"action"`
+	Actor     string                 `json:"actor"`
+	Changes   map[string]FieldChange `json:"changes"`
+	Snapshot  *Task                  `json:"snapshot"`   // task state after the change
+	CreatedAt string                 `json:"created_at"` // RFC3339
+}
+
+// FieldChange holds old and new value of a changed field
+type FieldChange struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+}
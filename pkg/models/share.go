@@ -0,0 +1,15 @@
+package models
+
+// Permissions of a user on a task
+const (
+	PermissionView  = "view"  // read the task
+	PermissionEdit  = "edit"  // change and complete the task
+	PermissionOwner = "owner" // also delete, assign and share the task, read only
+)
+
+// Share grants a user access to a task of another user
+type Share struct {
+	UserID     string `json:"user_id,omitempty"`
+	Login      string `json:"login"`
+	Permission string `json:"permission"` // PermissionView or PermissionEdit
+}
@@ -0,0 +1,19 @@
+package models
+
+// Tag is a label shared by tasks
+type Tag struct {
+	Name  string `json:"name"`
+	Tasks int    `json:"tasks"` // number of active tasks with the tag
+}
+
+// TagRenameInput is the body of POST /api/tags/rename
+type TagRenameInput struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// TagMergeInput is the body of POST /api/tags/merge
+type TagMergeInput struct {
+	From []string `json:"from"`
+	To   string   `json:"to"`
+}
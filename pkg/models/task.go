@@ -1,9 +1,52 @@
 package models
 
+// Task priorities, P1 is the most urgent
+const (
+	PriorityHighest = "P1"
+	PriorityDefault = "P4"
+)
+
 type Task struct {
 	ID      string `json:"id"`
-	Date    string `json:"date,omitempty"`
+	Date    string `json:"date,omitempty"`  // due date, empty for undated tasks
+	Start   string `json:"start,omitempty"` // start date, the task is hidden from the agenda before it
 	Title   string `json:"title"`
 	Comment string `json:"comment,omitempty"`
 	Repeat  string `json:"repeat,omitempty"`
+	Version string `json:"version,omitempty"` // incremented on every change, see ETag of GET /api/task
+
+	// SnoozedFrom - date of the snoozed occurrence of a recurring task, read only
+	// The series goes on from it when the task is done, changing date or repeat drops it
+	SnoozedFrom string `json:"snoozed_from,omitempty"`
+
+	// Priority - P1 to P4, new tasks get P4, empty on update keeps current priority
+	Priority string `json:"priority,omitempty"`
+
+	// Tags - nil on update keeps current tags, empty list removes them
+	Tags []string `json:"tags,omitempty"`
+
+	// Project - ID of the project, nil on update keeps current project, empty string removes the task from it
+	Project *string `json:"project,omitempty"`
+
+	// BlockedBy - IDs of tasks to be done first, nil on update keeps current blockers, empty list removes them
+	BlockedBy []string `json:"blocked_by,omitempty"`
+	Blocked   bool     `json:"blocked,omitempty"` // some blocker is not done yet, read only
+
+	Progress *Progress `json:"progress,omitempty"` // checklist progress, read only, nil without checklist
+
+	// Assignee - login of the user doing the task, nil on update keeps the assignee, empty string removes it
+	// Only the owner assigns the task, the assignee may change and complete it
+	Assignee   *string `json:"assignee,omitempty"`
+	Owner      string  `json:"owner,omitempty"`      // login of the owner, read only
+	Permission string  `json:"permission,omitempty"` // access of the requesting user: owner, edit or view, read only
+	OwnerID    string  `json:"-"`
+
+	CompletedAt string `json:"completed_at,omitempty"` // RFC3339, set for completed one-time tasks
+	DeletedAt   string `json:"deleted_at,omitempty"`   // RFC3339, set for tasks in the trash
+}
+
+// SnoozeInput - body of the "snooze" request
+// To is a relative offset "+3d", "+2w", "+1m", "next monday" or a date in YYYYMMDD format
+type SnoozeInput struct {
+	To string `json:"to"`
 }
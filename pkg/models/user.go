@@ -0,0 +1,25 @@
+package models
+
+// User roles
+const (
+	RoleAdmin    = "admin"    // manages users and backups, changes tasks
+	RoleEditor   = "editor"   // changes tasks
+	RoleReadOnly = "readonly" // only reads tasks
+)
+
+// User is an account, tasks, projects and tags of a user are visible to the user only
+type User struct {
+	ID           string `json:"id"`
+	Login        string `json:"login"`
+	Role         string `json:"role"`
+	CreatedAt    string `json:"created_at,omitempty"`
+	PasswordHash string `json:"-"`
+}
+
+// UserInput is the body of POST /api/admin/user, PUT /api/admin/user and POST /api/user/password
+type UserInput struct {
+	ID       string `json:"id,omitempty"`
+	Login    string `json:"login"`
+	Password string `json:"password"`
+	Role     string `json:"role"` // RoleEditor when empty on registration
+}
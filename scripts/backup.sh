@@ -2,7 +2,6 @@
 
 # Конфигурация
 BACKUP_DIR="./backups"
-DATE=$(date +%Y%m%d_%H%M%S)
 DB_FILE="data/scheduler.db"
 
 # Цвета для вывода
@@ -35,13 +34,21 @@ if [ ! -r "$DB_FILE" ]; then
     exit 1
 fi
 
-# Создаем бекап
-info "Копирование базы данных..."
-if cp "$DB_FILE" "$BACKUP_DIR/scheduler_${DATE}.db"; then
+# Создаем бекап средствами приложения: снимок через VACUUM INTO с проверкой целостности
+# Простое копирование файла работающей базы может дать несогласованную копию
+info "Создание снимка базы данных..."
+if docker compose ps --status running -q todo-scheduler 2>/dev/null | grep -q .; then
+    SNAPSHOT=$(docker compose exec -T todo-scheduler ./todo backup | tail -1)
+else
+    SNAPSHOT=$(TODO_DBFILE="$DB_FILE" TODO_BACKUP_DIR="$BACKUP_DIR" go run main.go backup | tail -1)
+fi
+
+BACKUP_FILE="$BACKUP_DIR/$(basename "$SNAPSHOT")"
+if [ -n "$SNAPSHOT" ] && [ -f "$BACKUP_FILE" ]; then
     # Устанавливаем нормальные права на бекап (читаемый для всех)
-    chmod 644 "$BACKUP_DIR/scheduler_${DATE}.db" 2>/dev/null || true
-    
-    success "Бекап создан: $BACKUP_DIR/scheduler_${DATE}.db"
+    chmod 644 "$BACKUP_FILE" 2>/dev/null || true
+
+    success "Бекап создан: $BACKUP_FILE"
 else
     error "Ошибка при создании бекапа"
     exit 1
@@ -50,8 +57,8 @@ fi
 # Статистика
 echo ""
 success "📊 Бекап успешно создан:"
-info "   🗄️  Файл: $BACKUP_DIR/scheduler_${DATE}.db"
-info "   📏 Размер: $(du -h "$BACKUP_DIR/scheduler_${DATE}.db" | cut -f1)"
+info "   🗄️  Файл: $BACKUP_FILE"
+info "   📏 Размер: $(du -h "$BACKUP_FILE" | cut -f1)"
 info "   📅 Дата: $(date +"%d.%m.%Y %H:%M")"
 
 # Информация о бекапах
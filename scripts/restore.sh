@@ -78,50 +78,36 @@ if [[ ! $REPLY =~ ^[Yy]$ ]]; then
     exit 0
 fi
 
-# Останавливаем приложение
-info "Останавливаем приложение..."
-docker compose down
+# Команда приложения: внутри запущенного контейнера или локально
+if docker compose ps --status running -q todo-scheduler 2>/dev/null | grep -q .; then
+    TODO_CMD=(docker compose exec -T todo-scheduler ./todo)
+    SNAPSHOT="/app/backups/$(basename "$BACKUP_FILE")"
+else
+    TODO_CMD=(env TODO_DBFILE="$DB_FILE" TODO_BACKUP_DIR="$BACKUP_DIR" go run main.go)
+    SNAPSHOT="$BACKUP_FILE"
+fi
 
-# Создаем резервную копию текущей базы (на всякий случай)
-if [ -f "$DB_FILE" ]; then
-    info "Создаю резервную копию текущей базы..."
-    cp "$DB_FILE" "$DB_FILE.backup.$(date +%Y%m%d_%H%M%S)"
-    success "Резервная копия создана"
+# Создаем снимок текущей базы (на всякий случай)
+info "Создаю резервную копию текущей базы..."
+if "${TODO_CMD[@]}" backup > /dev/null; then
+    success "Резервная копия создана в $BACKUP_DIR"
+else
+    error "Не удалось создать резервную копию текущей базы"
+    exit 1
 fi
 
-# Восстановление
+# Восстановление: приложение проверяет снимок и подменяет базу атомарно, без остановки
 info "Восстановление данных..."
 START_TIME=$(date +%s)
 
-if cp "$BACKUP_FILE" "$DB_FILE"; then
+if "${TODO_CMD[@]}" restore "$SNAPSHOT"; then
     DURATION=$(( $(date +%s) - START_TIME ))
     success "Данные восстановлены за ${DURATION}с"
-    
-    # Устанавливаем правильные права
-    chmod 644 "$DB_FILE"
-    chown $SUDO_USER:$SUDO_USER "$DB_FILE" 2>/dev/null || true
-    
-    # Проверяем что файл не пустой
-    if [ -s "$DB_FILE" ]; then
-        success "Файл базы данных проверен (не пустой)"
-    else
-        warning "Восстановленный файл базы данных пуст"
-    fi
 else
-    error "Ошибка при восстановлении данных"
-    # Пытаемся восстановить из backup
-    LATEST_BACKUP=$(find . -name "scheduler.db.backup.*" -type f -printf "%T@ %p\n" 2>/dev/null | sort -nr | head -1 | cut -d' ' -f2-)
-    if [ -n "$LATEST_BACKUP" ]; then
-        warning "Восстанавливаю из резервной копии..."
-        cp "$LATEST_BACKUP" "$DB_FILE"
-    fi
+    error "Ошибка при восстановлении данных, текущая база не изменена"
     exit 1
 fi
 
-# Запускаем приложение
-info "Запускаем приложение..."
-docker compose up -d
-
 # Финальная информация
 echo ""
 success "╔══════════════════════════════════════════════════╗"
@@ -132,4 +118,4 @@ info "📁 Файл: $(basename "$BACKUP_FILE")"
 info "📏 Размер: $(du -h "$BACKUP_FILE" | cut -f1)"
 info "⏱️ Время восстановления: ${DURATION}с"
 echo ""
-info "🌐 Приложение запущено и доступно на http://localhost:7540"
\ No newline at end of file
+info "🌐 Приложение доступно на http://localhost:7540"
\ No newline at end of file
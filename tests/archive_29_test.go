@@ -0,0 +1,60 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArchive(t *testing.T) {
+	id := addTask(t, task{title: "Провести ретроспективу", comment: "archive-29"})
+	other := addTask(t, task{title: "Подготовить демо", comment: "archive-29"})
+
+	ret, err := postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+
+	assert.Equal(t, []string{other}, taskIDs(getTasksPage(t, "search=archive-29").Tasks),
+		"Выполненная задача не должна попадать в список активных")
+	assert.Equal(t, []string{other}, taskIDs(getTasksPage(t, "search=archive-29&status=active").Tasks))
+	done := getTasksPage(t, "search=archive-29&status=done").Tasks
+	if assert.Equal(t, 1, len(done)) {
+		assert.Equal(t, id, done[0]["id"])
+		assert.NotEmpty(t, done[0]["completed_at"])
+	}
+	assert.Equal(t, http.StatusBadRequest, requestStatus(t, "api/tasks?status=archived", nil, http.MethodGet))
+
+	body, err := requestJSON("api/task?id="+id, nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), `"completed_at"`, "Выполненная задача должна оставаться доступной")
+
+	assert.Equal(t, http.StatusConflict, requestStatus(t, "api/task/done?id="+id, nil, http.MethodPost),
+		"Повторное выполнение задачи должно отклоняться")
+	assert.Equal(t, http.StatusBadRequest, requestStatus(t, "api/task", map[string]any{
+		"id":         other,
+		"title":      "Подготовить демо",
+		"blocked_by": []string{id},
+		"version":    taskVersion(t, other),
+	}, http.MethodPut), "Выполненная задача не может блокировать другие")
+
+	ret, err = postJSON("api/task/reopen?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.ElementsMatch(t, []string{id, other}, taskIDs(getTasksPage(t, "search=archive-29").Tasks))
+	assert.Empty(t, getTasksPage(t, "search=archive-29&status=done").Tasks)
+	assert.Equal(t, http.StatusConflict, requestStatus(t, "api/task/reopen?id="+id, nil, http.MethodPost),
+		"Открыть можно только выполненную задачу")
+	assert.Equal(t, http.StatusBadRequest, requestStatus(t, "api/task/reopen", nil, http.MethodPost))
+
+	history := getHistory(t, id)
+	if assert.NotEmpty(t, history) {
+		assert.Equal(t, "reopen", history[0].Action)
+		assert.Equal(t, "", history[0].Changes["completed_at"]["new"])
+	}
+
+	for _, id := range []string{id, other} {
+		_, err = postJSON("api/task?id="+id, nil, http.MethodDelete)
+		assert.NoError(t, err)
+	}
+}
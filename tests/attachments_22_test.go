@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func attachmentsDir() string {
+	if dir := os.Getenv("TODO_ATTACHMENTS_DIR"); dir != "" {
+		return dir
+	}
+	dbfile := DBFile
+	if envFile := os.Getenv("TODO_DBFILE"); envFile != "" {
+		dbfile = envFile
+	}
+	return filepath.Join(filepath.Dir(dbfile), "attachments")
+}
+
+func uploadFile(t *testing.T, id, name string, content []byte) (int, map[string]any) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile("file", name)
+	assert.NoError(t, err)
+	fw.Write(content)
+	assert.NoError(t, mw.Close())
+
+	req, err := http.NewRequest(http.MethodPost, getURL("api/task/attachments?id="+id), &body)
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	if len(Token) > 0 {
+		req.AddCookie(&http.Cookie{Name: "token", Value: Token})
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if !assert.NoError(t, err) {
+		return 0, nil
+	}
+	defer resp.Body.Close()
+
+	var m map[string]any
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&m))
+	return resp.StatusCode, m
+}
+
+func TestAttachments(t *testing.T) {
+	id := addTask(t, task{title: "Задача с вложениями"})
+	other := addTask(t, task{title: "Задача с тем же файлом"})
+
+	content := []byte("Протокол встречи\n")
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])
+
+	status, ret := uploadFile(t, id, "протокол.txt", content)
+	assert.Equal(t, http.StatusOK, status)
+	first, _ := ret["id"].(string)
+	if !assert.NotEmpty(t, first) {
+		return
+	}
+	assert.Equal(t, hash, ret["sha256"])
+	assert.Equal(t, float64(len(content)), ret["size"])
+
+	status, ret = uploadFile(t, other, "копия.txt", content)
+	assert.Equal(t, http.StatusOK, status)
+	second, _ := ret["id"].(string)
+
+	file := filepath.Join(attachmentsDir(), hash[:2], hash)
+	assert.FileExists(t, file, "Файл должен храниться под своей контрольной суммой")
+
+	body, err := requestJSON("api/task/attachments?id="+id, nil, http.MethodGet)
+	assert.NoError(t, err)
+	var list map[string][]map[string]any
+	assert.NoError(t, json.Unmarshal(body, &list))
+	if assert.Len(t, list["attachments"], 1) {
+		assert.Equal(t, "протокол.txt", list["attachments"][0]["name"])
+	}
+
+	req, err := http.NewRequest(http.MethodGet, getURL("api/attachment?id="+first), nil)
+	assert.NoError(t, err)
+	req.AddCookie(&http.Cookie{Name: "token", Value: Token})
+	resp, err := http.DefaultClient.Do(req)
+	if assert.NoError(t, err) {
+		data, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, content, data)
+		assert.Contains(t, resp.Header.Get("Content-Disposition"), "attachment")
+	}
+
+	assert.Equal(t, http.StatusBadRequest, requestStatus(t, "api/task/attachments?id="+id, map[string]any{}, http.MethodPost),
+		"Загрузка без multipart должна вызывать ошибку")
+	status, _ = uploadFile(t, "999999999", "нет.txt", content)
+	assert.Equal(t, http.StatusNotFound, status)
+
+	// Shared file stays while another attachment refers to it
+	ret, err = postJSON("api/attachment?id="+second, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.FileExists(t, file)
+	assert.Equal(t, http.StatusNotFound, requestStatus(t, "api/attachment?id="+second, nil, http.MethodGet))
+
+	// Purging the task removes its files
+	_, err = postJSON("api/task?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.FileExists(t, file, "Файл задачи в корзине должен сохраняться")
+	_, err = postJSON("api/trash?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.NoFileExists(t, file, "Файл удалённой задачи должен быть удалён")
+}
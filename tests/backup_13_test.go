@@ -0,0 +1,43 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackup(t *testing.T) {
+	kept := addTask(t, task{
+		title:   "Задача из бекапа",
+		comment: "должна остаться после восстановления",
+	})
+
+	ret, err := postJSON("api/admin/backup", nil, http.MethodPost)
+	assert.NoError(t, err)
+	name, _ := ret["name"].(string)
+	if !assert.NotEmpty(t, name, "Бекап должен вернуть имя файла") {
+		return
+	}
+	assert.Len(t, ret["sha256"], 64, "Бекап должен содержать контрольную сумму")
+
+	body, err := requestJSON("api/admin/backups", nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), name, "Бекап должен быть в списке")
+
+	lost := addTask(t, task{
+		title: "Задача после бекапа",
+	})
+
+	ret, err = postJSON("api/admin/restore?name="+name, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+
+	task, err := postJSON("api/task?id="+kept, nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.Equal(t, kept, task["id"], "Задача из бекапа должна быть восстановлена")
+	notFoundTask(t, lost)
+
+	assert.Equal(t, http.StatusBadRequest, requestStatus(t, "api/admin/restore?name=../scheduler.db", nil, http.MethodPost))
+	assert.Equal(t, http.StatusNotFound, requestStatus(t, "api/admin/restore?name=missing.db", nil, http.MethodPost))
+}
@@ -0,0 +1,100 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type batchResult struct {
+	Index  int    `json:"index"`
+	Op     string `json:"op"`
+	ID     string `json:"id"`
+	Status int    `json:"status"`
+	Error  string `json:"error"`
+}
+
+func postBatch(t *testing.T, values map[string]any) (int, map[string]json.RawMessage) {
+	data, err := json.Marshal(values)
+	assert.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, getURL("api/tasks/batch"), bytes.NewBuffer(data))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	if len(Token) > 0 {
+		req.AddCookie(&http.Cookie{Name: "token", Value: Token})
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if !assert.NoError(t, err) {
+		return 0, nil
+	}
+	defer resp.Body.Close()
+
+	var m map[string]json.RawMessage
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&m))
+	return resp.StatusCode, m
+}
+
+func TestBatch(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	now := time.Now().Format(`20060102`)
+	recurring := addTask(t, task{date: now, title: "Пакетная периодическая", repeat: "d 2"})
+	onetime := addTask(t, task{date: now, title: "Пакетная разовая"})
+
+	before, err := count(db)
+	assert.NoError(t, err)
+
+	// Atomic: one invalid operation cancels the whole batch
+	status, ret := postBatch(t, map[string]any{
+		"operations": []map[string]any{
+			{"op": "create", "task": map[string]any{"title": "Не должна появиться"}},
+			{"op": "done", "id": recurring},
+			{"op": "delete", "id": "999999999"},
+		},
+	})
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, "2", string(ret["index"]))
+	after, err := count(db)
+	assert.NoError(t, err)
+	assert.Equal(t, before, after, "Атомарный пакет с ошибкой не должен ничего менять")
+	var task Task
+	assert.NoError(t, db.Get(&task, `SELECT * FROM scheduler WHERE id=?`, recurring))
+	assert.Equal(t, now, task.Date)
+
+	// Per item: failed operations are skipped, the rest is applied
+	status, ret = postBatch(t, map[string]any{
+		"mode": "per_item",
+		"operations": []map[string]any{
+			{"op": "create", "task": map[string]any{"title": "Из пакета", "date": now}},
+			{"op": "create", "task": map[string]any{"title": ""}},
+			{"op": "update", "task": map[string]any{"id": onetime, "title": "Переименована пакетом", "date": now, "version": taskVersion(t, onetime)}},
+			{"op": "done", "id": recurring},
+			{"op": "delete", "id": "999999999"},
+			{"op": "ooops"},
+		},
+	})
+	assert.Equal(t, http.StatusOK, status)
+	var results []batchResult
+	assert.NoError(t, json.Unmarshal(ret["results"], &results))
+	if !assert.Len(t, results, 6) {
+		return
+	}
+	expected := []int{http.StatusOK, http.StatusBadRequest, http.StatusOK, http.StatusOK, http.StatusNotFound, http.StatusBadRequest}
+	for i, res := range results {
+		assert.Equal(t, expected[i], res.Status, "Операция %d: %s", i, res.Error)
+	}
+
+	assert.NoError(t, db.Get(&task, `SELECT * FROM scheduler WHERE id=?`, results[0].ID))
+	assert.Equal(t, "Из пакета", task.Title)
+	assert.NoError(t, db.Get(&task, `SELECT * FROM scheduler WHERE id=?`, onetime))
+	assert.Equal(t, "Переименована пакетом", task.Title)
+	assert.NoError(t, db.Get(&task, `SELECT * FROM scheduler WHERE id=?`, recurring))
+	assert.Greater(t, task.Date, now)
+}
@@ -0,0 +1,100 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type checklistItem struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Done     bool   `json:"done"`
+	Position int    `json:"position"`
+}
+
+func getChecklist(t *testing.T, id string) []checklistItem {
+	body, err := requestJSON("api/checklist?task="+id, nil, http.MethodGet)
+	assert.NoError(t, err)
+
+	var m map[string][]checklistItem
+	assert.NoError(t, json.Unmarshal(body, &m))
+	return m["items"]
+}
+
+func taskProgress(t *testing.T, id string) map[string]any {
+	task, err := postJSON("api/task?id="+id, nil, http.MethodGet)
+	assert.NoError(t, err)
+	progress, _ := task["progress"].(map[string]any)
+	return progress
+}
+
+func TestChecklist(t *testing.T) {
+	now := time.Now()
+	id := addTask(t, task{
+		date:   now.Format(`20060102`),
+		title:  "Еженедельный релиз",
+		repeat: "d 7",
+	})
+	assert.Nil(t, taskProgress(t, id), "У задачи без чек-листа нет прогресса")
+
+	var items []string
+	for _, title := range []string{"Заморозить ветку", "Прогнать тесты", "Опубликовать"} {
+		ret, err := postJSON("api/checklist?task="+id, map[string]any{"title": title}, http.MethodPost)
+		assert.NoError(t, err)
+		item, _ := ret["id"].(string)
+		assert.NotEmpty(t, item)
+		items = append(items, item)
+	}
+	if len(items) != 3 {
+		return
+	}
+	assert.Equal(t, map[string]any{"done": 0.0, "total": 3.0}, taskProgress(t, id))
+
+	ret, err := postJSON("api/checklist?task="+id, map[string]any{"title": " "}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"], "Пустой пункт должен вызывать ошибку")
+
+	ret, err = postJSON("api/checklist/toggle?id="+items[0], nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Equal(t, true, ret["done"])
+	ret, err = postJSON("api/checklist", map[string]any{"id": items[1], "title": "Прогнать все тесты", "done": true}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Equal(t, map[string]any{"done": 2.0, "total": 3.0}, taskProgress(t, id))
+
+	ret, err = postJSON("api/checklist/reorder?task="+id, map[string]any{
+		"items": []string{items[2], items[0], items[1]},
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	checklist := getChecklist(t, id)
+	if assert.Len(t, checklist, 3) {
+		assert.Equal(t, items[2], checklist[0].ID)
+		assert.Equal(t, "Прогнать все тесты", checklist[2].Title)
+	}
+	assert.Equal(t, http.StatusBadRequest, requestStatus(t, "api/checklist/reorder?task="+id, map[string]any{
+		"items": []string{items[0], items[0], items[1]},
+	}, http.MethodPost), "Порядок должен содержать каждый пункт один раз")
+
+	// Completing a recurring task starts the next occurrence with a clean checklist
+	ret, err = postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Equal(t, map[string]any{"done": 0.0, "total": 3.0}, taskProgress(t, id))
+
+	ret, err = postJSON("api/checklist?id="+items[2], nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Len(t, getChecklist(t, id), 2)
+	assert.Equal(t, http.StatusNotFound, requestStatus(t, "api/checklist/toggle?id="+items[2], nil, http.MethodPost))
+
+	// Other tests read task lists as strings only, drop the task with checklist
+	_, err = postJSON("api/task?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	_, err = postJSON("api/trash?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+}
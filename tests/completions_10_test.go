@@ -0,0 +1,72 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type completion struct {
+	ID          string `json:"id"`
+	TaskID      string `json:"task_id"`
+	Title       string `json:"title"`
+	Date        string `json:"date"`
+	CompletedAt string `json:"completed_at"`
+	Note        string `json:"note"`
+}
+
+func getCompletions(t *testing.T, query string) []completion {
+	body, err := requestJSON("api/completions?"+query, nil, http.MethodGet)
+	assert.NoError(t, err)
+
+	var m map[string][]completion
+	err = json.Unmarshal(body, &m)
+	assert.NoError(t, err)
+	return m["completions"]
+}
+
+func TestCompletions(t *testing.T) {
+	now := time.Now()
+	today := now.Format(`20060102`)
+
+	id := addTask(t, task{
+		date:   today,
+		title:  "Проверить огнетушители",
+		repeat: "d 2",
+	})
+
+	ret, err := postJSON("api/task/done?id="+id, map[string]any{"note": "всё в порядке"}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	ret, err = postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+
+	log := getCompletions(t, "id="+id)
+	assert.Equal(t, 2, len(log))
+	if len(log) == 2 {
+		assert.Equal(t, now.AddDate(0, 0, 2).Format(`20060102`), log[0].Date)
+		assert.Empty(t, log[0].Note)
+		assert.Equal(t, today, log[1].Date)
+		assert.Equal(t, "всё в порядке", log[1].Note)
+		assert.Equal(t, "Проверить огнетушители", log[1].Title)
+		assert.NotEmpty(t, log[1].CompletedAt)
+	}
+
+	log = getCompletions(t, "from="+today+"&to="+today)
+	found := false
+	for _, c := range log {
+		assert.Equal(t, today, c.Date)
+		if c.TaskID == id {
+			found = true
+		}
+	}
+	assert.True(t, found)
+
+	ret, err = postJSON("api/completions?from=ooops", nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+}
@@ -0,0 +1,108 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConcurrency(t *testing.T) {
+	const (
+		workers    = 20
+		iterations = 10
+	)
+
+	ids := make([]string, 4)
+	for i := range ids {
+		ids[i] = addTask(t, task{
+			date:   time.Now().Format(`20060102`),
+			title:  fmt.Sprintf("Общая задача %d", i),
+			repeat: "d 1",
+		})
+	}
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		failed = map[int]int{}
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				id := ids[(w+i)%len(ids)]
+
+				var status int
+				switch i % 4 {
+				case 0:
+					status = requestStatus(t, "api/task/done?id="+id, nil, http.MethodPost)
+				case 1:
+					status = requestStatus(t, "api/task", map[string]any{
+						"id":      id,
+						"date":    time.Now().Format(`20060102`),
+						"title":   fmt.Sprintf("Изменено воркером %d", w),
+						"repeat":  "d 1",
+						"version": taskVersion(t, id),
+					}, http.MethodPut)
+					// Another worker may change the task in between, that is expected
+					if status == http.StatusConflict {
+						status = http.StatusOK
+					}
+				case 2:
+					status = requestStatus(t, "api/tasks", nil, http.MethodGet)
+				case 3:
+					status = requestStatus(t, "api/task", map[string]any{
+						"title": fmt.Sprintf("Задача воркера %d", w),
+					}, http.MethodPost)
+				}
+
+				if status != http.StatusOK {
+					mu.Lock()
+					failed[status]++
+					mu.Unlock()
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	assert.Empty(t, failed, "При конкурентных запросах не должно быть ошибок (статус: количество)")
+}
+
+func TestConcurrentDone(t *testing.T) {
+	const clicks = 8
+
+	now := time.Now()
+	id := addTask(t, task{
+		date:   now.Format(`20060102`),
+		title:  "Задача с двойным кликом",
+		repeat: "d 1",
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < clicks; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Equal(t, http.StatusOK, requestStatus(t, "api/task/done?id="+id, nil, http.MethodPost))
+		}()
+	}
+	wg.Wait()
+
+	db := openDB(t)
+	defer db.Close()
+
+	var task Task
+	assert.NoError(t, db.Get(&task, `SELECT * FROM scheduler WHERE id=?`, id))
+	assert.Equal(t, now.AddDate(0, 0, clicks).Format(`20060102`), task.Date,
+		"Каждое выполнение должно сдвигать задачу ровно на один период")
+
+	var completed int
+	assert.NoError(t, db.Get(&completed, `SELECT count(*) FROM completions WHERE task_id=?`, id))
+	assert.Equal(t, clicks, completed)
+}
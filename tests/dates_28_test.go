@@ -0,0 +1,69 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartDate(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	day := func(n int) string { return time.Now().AddDate(0, 0, n).Format(`20060102`) }
+
+	ret, err := postJSON("api/task", map[string]any{"title": "Подготовить отчет", "comment": "dates-28", "start": day(2), "date": day(5)}, http.MethodPost)
+	assert.NoError(t, err)
+	deferred, _ := ret["id"].(string)
+	assert.NotEmpty(t, deferred, ret["error"])
+	started := addTask(t, task{title: "Разобрать почту", comment: "dates-28"})
+
+	ids := taskIDs(getTasksPage(t, "search=dates-28").Tasks)
+	assert.NotContains(t, ids, deferred, "Задача не должна показываться до даты начала")
+	assert.Contains(t, ids, started)
+	page := getTasksPage(t, "search=dates-28&deferred=true")
+	assert.Contains(t, taskIDs(page.Tasks), deferred)
+	for _, task := range page.Tasks {
+		if task["id"] == deferred {
+			assert.Equal(t, day(2), task["start"])
+			assert.Equal(t, day(5), task["date"])
+		}
+	}
+
+	for _, values := range []map[string]any{
+		{"title": "Начало после срока", "start": day(6), "date": day(5)},
+		{"title": "Неверное начало", "start": "завтра", "date": day(5)},
+	} {
+		assert.Equal(t, http.StatusBadRequest, requestStatus(t, "api/task", values, http.MethodPost), values["title"])
+	}
+	assert.Equal(t, http.StatusBadRequest, requestStatus(t, "api/tasks?deferred=maybe", nil, http.MethodGet))
+
+	// Overdue only after the due date
+	_, err = db.Exec(`UPDATE scheduler SET date = ? WHERE id = ?`, day(-1), started)
+	assert.NoError(t, err)
+	ids = taskIDs(getTasksPage(t, "search=dates-28&overdue=true&deferred=true").Tasks)
+	assert.Equal(t, []string{started}, ids, "Просроченной считается задача после срока")
+
+	// Recurrence shifts both dates
+	ret, err = postJSON("api/task", map[string]any{"title": "Полить цветы", "comment": "dates-28", "start": day(-2), "date": day(0), "repeat": "d 7"}, http.MethodPost)
+	assert.NoError(t, err)
+	recurring, _ := ret["id"].(string)
+	assert.NotEmpty(t, recurring, ret["error"])
+	_, err = postJSON("api/task/done?id="+recurring, nil, http.MethodPost)
+	assert.NoError(t, err)
+
+	var dates struct {
+		Date  string `db:"date"`
+		Start string `db:"start"`
+	}
+	assert.NoError(t, db.Get(&dates, `SELECT date, start FROM scheduler WHERE id = ?`, recurring))
+	assert.Equal(t, day(7), dates.Date)
+	assert.Equal(t, day(5), dates.Start, "Дата начала должна сдвинуться вместе со сроком")
+
+	for _, id := range []string{deferred, started, recurring} {
+		_, err = postJSON("api/task?id="+id, nil, http.MethodDelete)
+		assert.NoError(t, err)
+	}
+}
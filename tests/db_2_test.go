@@ -11,11 +11,20 @@ import (
 )
 
 type Task struct {
-	ID      int64  `db:"id"`
-	Date    string `db:"date"`
-	Title   string `db:"title"`
-	Comment string `db:"comment"`
-	Repeat  string `db:"repeat"`
+	ID          int64  `db:"id"`
+	Date        string `db:"date"`
+	Title       string `db:"title"`
+	Comment     string `db:"comment"`
+	Repeat      string `db:"repeat"`
+	DeletedAt   string `db:"deleted_at"`
+	Version     int64  `db:"version"`
+	ProjectID   *int64 `db:"project_id"`
+	Priority    int64  `db:"priority"`
+	OwnerID     int64  `db:"owner_id"`
+	Assignee    *int64 `db:"assignee_id"`
+	Start       string `db:"start"`
+	CompletedAt string `db:"completed_at"`
+	SnoozedFrom string `db:"snoozed_from"`
 }
 
 func count(db *sqlx.DB) (int, error) {
@@ -0,0 +1,114 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type planTask struct {
+	ID        string   `json:"id"`
+	Blocked   bool     `json:"blocked"`
+	BlockedBy []string `json:"blocked_by"`
+}
+
+func getPlan(t *testing.T, query string) []planTask {
+	body, err := requestJSON("api/tasks/plan?"+query, nil, http.MethodGet)
+	assert.NoError(t, err)
+	var resp struct {
+		Tasks []planTask `json:"tasks"`
+	}
+	assert.NoError(t, json.Unmarshal(body, &resp))
+	return resp.Tasks
+}
+
+func planIDs(tasks []planTask) []string {
+	ids := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+	return ids
+}
+
+func TestDependencies(t *testing.T) {
+	build := addTask(t, task{title: "Собрать релиз"})
+	newTask := func(title string, blockedBy ...string) string {
+		ret, err := postJSON("api/task", map[string]any{"title": title, "blocked_by": blockedBy}, http.MethodPost)
+		assert.NoError(t, err)
+		id, _ := ret["id"].(string)
+		assert.NotEmpty(t, id, ret["error"])
+		return id
+	}
+	check := newTask("Проверить релиз", build)
+	publish := newTask("Опубликовать релиз", check, build)
+
+	task, err := postJSON("api/task?id="+publish, nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.Equal(t, true, task["blocked"])
+	assert.Equal(t, []any{build, check}, task["blocked_by"])
+
+	ret, err := postJSON("api/task", map[string]any{"title": "Без блокера", "blocked_by": []string{"999999999"}}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"], "Несуществующая задача не может блокировать")
+
+	// Cycles are rejected
+	for _, blockedBy := range [][]string{{publish}, {build}} {
+		_, ret = putTask(t, map[string]any{
+			"id":         build,
+			"title":      "Собрать релиз",
+			"blocked_by": blockedBy,
+			"version":    taskVersion(t, build),
+		}, "")
+		assert.NotEmpty(t, ret["error"], "Цикл зависимостей должен вызывать ошибку")
+	}
+
+	assert.Equal(t, []string{build, check, publish}, planIDs(getPlan(t, "id="+publish)))
+	assert.Equal(t, []string{build, check}, planIDs(getPlan(t, "id="+check)))
+	assert.Equal(t, http.StatusNotFound, requestStatus(t, "api/tasks/plan?id=999999999", nil, http.MethodGet))
+
+	plan := getPlan(t, "")
+	pos := make(map[string]int)
+	for i, task := range plan {
+		pos[task.ID] = i
+	}
+	assert.Less(t, pos[build], pos[check], "Блокирующая задача должна идти раньше")
+	assert.Less(t, pos[check], pos[publish], "Блокирующая задача должна идти раньше")
+
+	// Completing the blocker releases its dependents
+	ret, err = postJSON("api/task/done?id="+build, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+
+	plan = getPlan(t, "id="+publish)
+	assert.Equal(t, []string{check, publish}, planIDs(plan))
+	if len(plan) == 2 {
+		assert.False(t, plan[0].Blocked)
+		assert.Empty(t, plan[0].BlockedBy)
+		assert.True(t, plan[1].Blocked)
+		assert.Equal(t, []string{check}, plan[1].BlockedBy)
+	}
+
+	// Empty list removes blockers
+	_, ret = putTask(t, map[string]any{
+		"id":         publish,
+		"title":      "Опубликовать релиз",
+		"blocked_by": []string{},
+		"version":    taskVersion(t, publish),
+	}, "")
+	assert.Empty(t, ret)
+	task, err = postJSON("api/task?id="+publish, nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.Nil(t, task["blocked"])
+	assert.Nil(t, task["blocked_by"])
+
+	for _, id := range []string{check, publish} {
+		_, err = postJSON("api/task?id="+id, nil, http.MethodDelete)
+		assert.NoError(t, err)
+	}
+	for _, id := range []string{build, check, publish} {
+		_, err = postJSON("api/trash?id="+id, nil, http.MethodDelete)
+		assert.NoError(t, err)
+	}
+}
@@ -0,0 +1,50 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func requestStatus(t *testing.T, apipath string, values map[string]any, method string) int {
+	var data []byte
+	if len(values) > 0 {
+		var err error
+		data, err = json.Marshal(values)
+		assert.NoError(t, err)
+	}
+
+	req, err := http.NewRequest(method, getURL(apipath), bytes.NewBuffer(data))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	if len(Token) > 0 {
+		req.AddCookie(&http.Cookie{Name: "token", Value: Token})
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if !assert.NoError(t, err) {
+		return 0
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestErrorStatus(t *testing.T) {
+	missing := "999999999"
+
+	assert.Equal(t, http.StatusNotFound, requestStatus(t, "api/task?id="+missing, nil, http.MethodGet))
+	assert.Equal(t, http.StatusNotFound, requestStatus(t, "api/task/done?id="+missing, nil, http.MethodPost))
+	assert.Equal(t, http.StatusNotFound, requestStatus(t, "api/task?id="+missing, nil, http.MethodDelete))
+	assert.Equal(t, http.StatusNotFound, requestStatus(t, "api/task", map[string]any{
+		"id":      missing,
+		"title":   "Нет такой задачи",
+		"version": "1",
+	}, http.MethodPut))
+	assert.Equal(t, http.StatusNotFound, requestStatus(t, "api/trash/restore?id="+missing, nil, http.MethodPost))
+
+	assert.Equal(t, http.StatusBadRequest, requestStatus(t, "api/task", nil, http.MethodGet))
+	assert.Equal(t, http.StatusBadRequest, requestStatus(t, "api/tasks?cursor=ooops", nil, http.MethodGet))
+}
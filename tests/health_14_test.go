@@ -0,0 +1,33 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealth(t *testing.T) {
+	_, err := postJSON("api/admin/backup", nil, http.MethodPost)
+	assert.NoError(t, err)
+
+	resp, err := http.Get(getURL("api/health"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var health struct {
+		Status   string            `json:"status"`
+		Database string            `json:"database"`
+		Backup   map[string]string `json:"backup"`
+	}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&health))
+	assert.Equal(t, "ok", health.Status)
+	assert.Equal(t, "ok", health.Database)
+	assert.Equal(t, "ok", health.Backup["last_status"], "Последний бекап должен быть успешным")
+	assert.NotEmpty(t, health.Backup["last_at"])
+	assert.NotEmpty(t, health.Backup["last_name"])
+}
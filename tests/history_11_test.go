@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type revision struct {
+	ID      string                       `json:"id"`
+	Action  string                       `json:"action"`
+	Actor   string                       `json:"actor"`
+	Changes map[string]map[string]string `json:"changes"`
+}
+
+func getHistory(t *testing.T, id string) []revision {
+	body, err := requestJSON("api/task/history?id="+id, nil, http.MethodGet)
+	assert.NoError(t, err)
+
+	var m map[string][]revision
+	err = json.Unmarshal(body, &m)
+	assert.NoError(t, err)
+	return m["revisions"]
+}
+
+func TestHistory(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	now := time.Now()
+	id := addTask(t, task{
+		date:   now.Format(`20060102`),
+		title:  "Сдать отчёт",
+		repeat: "d 7",
+	})
+
+	ret, err := postJSON("api/task", map[string]any{
+		"id":      id,
+		"date":    now.Format(`20060102`),
+		"title":   "Сдать квартальный отчёт",
+		"repeat":  "d 7",
+		"version": taskVersion(t, id),
+	}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+
+	ret, err = postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+
+	history := getHistory(t, id)
+	if !assert.Equal(t, 3, len(history)) {
+		return
+	}
+	assert.Equal(t, "done", history[0].Action)
+	assert.Equal(t, now.AddDate(0, 0, 7).Format(`20060102`), history[0].Changes["date"]["new"])
+	assert.Equal(t, "update", history[1].Action)
+	assert.Equal(t, "Сдать отчёт", history[1].Changes["title"]["old"])
+	assert.Equal(t, "Сдать квартальный отчёт", history[1].Changes["title"]["new"])
+	assert.Equal(t, "create", history[2].Action)
+	assert.NotEmpty(t, history[2].Actor)
+
+	ret, err = postJSON("api/task/revert?id="+id+"&revision="+history[2].ID, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+
+	var task Task
+	err = db.Get(&task, `SELECT * FROM scheduler WHERE id=?`, id)
+	assert.NoError(t, err)
+	assert.Equal(t, "Сдать отчёт", task.Title)
+
+	history = getHistory(t, id)
+	assert.Equal(t, 4, len(history))
+	assert.Equal(t, "revert", history[0].Action)
+
+	ret, err = postJSON("api/task/revert?id="+id+"&revision=999999999", nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+}
+
+func TestRevertTags(t *testing.T) {
+	id := addTask(t, task{title: "Разобрать заметки"})
+	history := getHistory(t, id)
+	if !assert.Equal(t, 1, len(history)) {
+		return
+	}
+
+	ret, err := postJSON("api/task", map[string]any{
+		"id":      id,
+		"date":    time.Now().Format(`20060102`),
+		"title":   "Разобрать заметки",
+		"tags":    []string{"работа"},
+		"version": taskVersion(t, id),
+	}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+
+	ret, err = postJSON("api/task/revert?id="+id+"&revision="+history[0].ID, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+
+	body, err := requestJSON("api/task?id="+id, nil, http.MethodGet)
+	assert.NoError(t, err)
+	var task tagged
+	assert.NoError(t, json.Unmarshal(body, &task))
+	assert.Empty(t, task.Tags, "Откат к ревизии без тегов должен снимать теги")
+
+	_, err = postJSON("api/task?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+}
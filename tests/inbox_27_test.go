@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// getInbox returns undated tasks
+func getInbox(t *testing.T, query string) []map[string]string {
+	body, err := requestJSON("api/tasks/inbox"+query, nil, http.MethodGet)
+	assert.NoError(t, err)
+
+	var page tasksPage
+	assert.NoError(t, json.Unmarshal(body, &page))
+	return page.Tasks
+}
+
+// taskIDs returns IDs of the listed tasks
+func taskIDs(tasks []map[string]string) []string {
+	ids := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task["id"])
+	}
+	return ids
+}
+
+func TestInbox(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	id := addTask(t, task{date: "someday", title: "Выучить испанский", comment: "inbox-27"})
+	dated := addTask(t, task{title: "Позвонить в банк", comment: "inbox-27"})
+
+	var date string
+	assert.NoError(t, db.Get(&date, `SELECT date FROM scheduler WHERE id = ?`, id))
+	assert.Empty(t, date, "Задача без даты должна храниться без даты")
+
+	assert.NotContains(t, taskIDs(getTasksPage(t, "search=inbox-27").Tasks), id, "Задача без даты не должна попадать в список по датам")
+	assert.Contains(t, taskIDs(getTasksPage(t, "search=inbox-27").Tasks), dated)
+	inbox := taskIDs(getInbox(t, "?search=inbox-27"))
+	assert.Contains(t, inbox, id)
+	assert.NotContains(t, inbox, dated)
+	assert.Contains(t, taskIDs(getInbox(t, "?search=испанск")), id)
+
+	task, err := requestJSON("api/task?id="+id, nil, http.MethodGet)
+	assert.NoError(t, err)
+	var m map[string]string
+	assert.NoError(t, json.Unmarshal(task, &m))
+	assert.Empty(t, m["date"])
+
+	// Task sent back as it was read stays in the inbox
+	var read map[string]any
+	assert.NoError(t, json.Unmarshal(task, &read))
+	read["comment"] = "inbox-27 изменено"
+	ret, err := postJSON("api/task", read, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	read["version"] = taskVersion(t, id)
+	status, _ := postBatch(t, map[string]any{"operations": []map[string]any{{"op": "update", "task": read}}})
+	assert.Equal(t, http.StatusOK, status)
+	assert.NoError(t, db.Get(&date, `SELECT date FROM scheduler WHERE id = ?`, id))
+	assert.Empty(t, date, "Задача без даты не должна получать дату при обновлении без даты")
+	assert.Contains(t, taskIDs(getInbox(t, "?search=inbox-27")), id)
+
+	// Dated task updated without date still moves to today
+	ret, err = postJSON("api/task", map[string]any{"id": dated, "title": "Позвонить в банк", "comment": "inbox-27", "version": taskVersion(t, dated)}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.NoError(t, db.Get(&date, `SELECT date FROM scheduler WHERE id = ?`, dated))
+	assert.Equal(t, time.Now().Format(`20060102`), date)
+
+	assert.Equal(t, http.StatusBadRequest, requestStatus(t, "api/task",
+		map[string]any{"title": "Повторять когда-нибудь", "date": "someday", "repeat": "d 1"}, http.MethodPost),
+		"Повторяющаяся задача должна иметь дату")
+
+	// Scheduling moves the task from the inbox to the agenda
+	tomorrow := time.Now().AddDate(0, 0, 1).Format(`20060102`)
+	ret, err = postJSON("api/task", map[string]any{"id": id, "title": "Выучить испанский", "comment": "inbox-27", "date": tomorrow, "version": taskVersion(t, id)}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.NotContains(t, taskIDs(getInbox(t, "?search=inbox-27")), id)
+	assert.Contains(t, taskIDs(getTasksPage(t, "search=inbox-27").Tasks), id)
+
+	// And back to the inbox
+	ret, err = postJSON("api/task", map[string]any{"id": id, "title": "Выучить испанский", "comment": "inbox-27", "date": "someday", "version": taskVersion(t, id)}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Contains(t, taskIDs(getInbox(t, "?search=inbox-27")), id)
+
+	for _, id := range []string{id, dated} {
+		_, err = postJSON("api/task?id="+id, nil, http.MethodDelete)
+		assert.NoError(t, err)
+	}
+}
@@ -0,0 +1,74 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type tasksPage struct {
+	Tasks []map[string]string `json:"tasks"`
+	Next  string              `json:"next"`
+}
+
+func getTasksPage(t *testing.T, query string) tasksPage {
+	body, err := requestJSON("api/tasks?"+query, nil, http.MethodGet)
+	assert.NoError(t, err)
+
+	var page tasksPage
+	err = json.Unmarshal(body, &page)
+	assert.NoError(t, err)
+	return page
+}
+
+func TestPagination(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	_, err := db.Exec("DELETE FROM scheduler")
+	assert.NoError(t, err)
+
+	now := time.Now()
+	for i := 0; i < 7; i++ {
+		addTask(t, task{
+			date:  now.AddDate(0, 0, i%3).Format(`20060102`),
+			title: fmt.Sprintf("Задача %d", i),
+		})
+	}
+
+	seen := make(map[string]bool)
+	var last map[string]string
+	query := "limit=3"
+	pages := 0
+	for {
+		page := getTasksPage(t, query)
+		pages++
+		assert.LessOrEqual(t, len(page.Tasks), 3)
+		for _, task := range page.Tasks {
+			assert.False(t, seen[task["id"]], "Задача %s встречается дважды", task["id"])
+			seen[task["id"]] = true
+			if last != nil {
+				assert.LessOrEqual(t, last["date"], task["date"], "Нарушен порядок сортировки")
+			}
+			last = task
+		}
+		if page.Next == "" {
+			break
+		}
+		query = "limit=3&cursor=" + page.Next
+	}
+	assert.Equal(t, 7, len(seen))
+	assert.Equal(t, 3, pages)
+
+	m, err := postJSON("api/tasks?cursor=ooops", nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, m["error"])
+
+	m, err = postJSON("api/tasks?limit=0", nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, m["error"])
+}
@@ -0,0 +1,76 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriority(t *testing.T) {
+	day := time.Now().AddDate(0, 0, 40)
+	ids := make(map[string]string)
+	for _, priority := range []string{"", "p1", "3", "P2"} {
+		ret, err := postJSON("api/task", map[string]any{
+			"date":     day.Format(`20060102`),
+			"title":    "Задача с приоритетом " + priority,
+			"priority": priority,
+		}, http.MethodPost)
+		assert.NoError(t, err)
+		id, _ := ret["id"].(string)
+		assert.NotEmpty(t, id)
+		ids[priority] = id
+	}
+
+	ret, err := postJSON("api/task", map[string]any{"title": "Неверный приоритет", "priority": "P5"}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"], "Приоритет вне P1-P4 должен вызывать ошибку")
+
+	// Tasks of the same day are ordered by priority
+	tasks := getTasks(t, day.Format(`02.01.2006`))
+	var order []string
+	for _, task := range tasks {
+		order = append(order, task["priority"])
+	}
+	assert.Equal(t, []string{"P1", "P2", "P3", "P4"}, order)
+	if len(tasks) == 4 {
+		assert.Equal(t, ids["p1"], tasks[0]["id"])
+		assert.Equal(t, ids[""], tasks[3]["id"])
+	}
+
+	// Update without priority keeps it
+	_, ret = putTask(t, map[string]any{
+		"id":      ids["p1"],
+		"date":    day.Format(`20060102`),
+		"title":   "Срочная задача",
+		"version": taskVersion(t, ids["p1"]),
+	}, "")
+	assert.Empty(t, ret)
+	task, err := postJSON("api/task?id="+ids["p1"], nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.Equal(t, "P1", task["priority"])
+
+	// Priority order walks every task once, most urgent first
+	seen := make(map[string]bool)
+	last := "P1"
+	cursor := ""
+	for {
+		page := getTasksPage(t, "order=priority&limit=3&cursor="+cursor)
+		for _, task := range page.Tasks {
+			assert.False(t, seen[task["id"]], "Задача не должна повторяться на страницах")
+			seen[task["id"]] = true
+			assert.LessOrEqual(t, last, task["priority"], "Задачи должны идти по возрастанию приоритета")
+			last = task["priority"]
+		}
+		if page.Next == "" {
+			break
+		}
+		cursor = page.Next
+	}
+	for _, id := range ids {
+		assert.True(t, seen[id])
+	}
+
+	assert.Equal(t, http.StatusBadRequest, requestStatus(t, "api/tasks?order=title", nil, http.MethodGet))
+}
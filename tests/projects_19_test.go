@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type project struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Color    string `json:"color"`
+	Archived bool   `json:"archived"`
+	Tasks    int    `json:"tasks"`
+}
+
+func getProjects(t *testing.T, query string) map[string]project {
+	body, err := requestJSON("api/projects?"+query, nil, http.MethodGet)
+	assert.NoError(t, err)
+
+	var m map[string][]project
+	assert.NoError(t, json.Unmarshal(body, &m))
+	projects := make(map[string]project)
+	for _, p := range m["projects"] {
+		projects[p.ID] = p
+	}
+	return projects
+}
+
+func TestProjects(t *testing.T) {
+	ret, err := postJSON("api/project", map[string]any{"name": "Работа", "color": "#3366ff"}, http.MethodPost)
+	assert.NoError(t, err)
+	work, _ := ret["id"].(string)
+	ret, err = postJSON("api/project", map[string]any{"name": "Дом"}, http.MethodPost)
+	assert.NoError(t, err)
+	home, _ := ret["id"].(string)
+	if !assert.NotEmpty(t, work) || !assert.NotEmpty(t, home) {
+		return
+	}
+
+	ret, err = postJSON("api/project", map[string]any{"name": "Цветной", "color": "синий"}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"], "Неверный цвет должен вызывать ошибку")
+	ret, err = postJSON("api/project", map[string]any{"name": " "}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"], "Пустое имя проекта должно вызывать ошибку")
+
+	ret, err = postJSON("api/task", map[string]any{"title": "Отчёт", "project": work}, http.MethodPost)
+	assert.NoError(t, err)
+	report, _ := ret["id"].(string)
+	chores := addTask(t, task{title: "Полить цветы"})
+
+	ret, err = postJSON("api/task", map[string]any{"title": "Без проекта", "project": "999999999"}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"], "Задачу нельзя добавить в несуществующий проект")
+
+	ret, err = postJSON("api/task/move?id="+chores+"&project="+home, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+
+	tasks := getTasksPage(t, "project="+home).Tasks
+	if assert.Len(t, tasks, 1, "Фильтр по проекту должен вернуть перенесённую задачу") {
+		assert.Equal(t, chores, tasks[0]["id"])
+		assert.Equal(t, home, tasks[0]["project"])
+	}
+	projects := getProjects(t, "")
+	assert.Equal(t, 1, projects[work].Tasks)
+	assert.Equal(t, "#3366ff", projects[work].Color)
+
+	// Update without project keeps the task in its project
+	_, ret = putTask(t, map[string]any{
+		"id":      report,
+		"title":   "Квартальный отчёт",
+		"version": taskVersion(t, report),
+	}, "")
+	assert.Empty(t, ret)
+	task, err := postJSON("api/task?id="+report, nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.Equal(t, work, task["project"])
+
+	// Archived projects are hidden and accept no new tasks
+	ret, err = postJSON("api/project", map[string]any{"id": home, "name": "Дом", "archived": true}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.NotContains(t, getProjects(t, ""), home)
+	assert.True(t, getProjects(t, "archived=1")[home].Archived)
+	assert.Equal(t, http.StatusBadRequest, requestStatus(t, "api/task/move?id="+report+"&project="+home, nil, http.MethodPost))
+
+	// Deleted project leaves its tasks without project
+	ret, err = postJSON("api/project?id="+work, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	task, err = postJSON("api/task?id="+report, nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.Nil(t, task["project"])
+	assert.Equal(t, http.StatusNotFound, requestStatus(t, "api/project?id="+work, nil, http.MethodGet))
+
+	ret, err = postJSON("api/task/move?id="+chores+"&project=", nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Empty(t, getTasksPage(t, "project="+home).Tasks)
+
+	history := getHistory(t, chores)
+	if assert.NotEmpty(t, history) {
+		assert.Equal(t, home, history[0].Changes["project"]["old"])
+	}
+}
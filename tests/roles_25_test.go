@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoles(t *testing.T) {
+	login := fmt.Sprintf("reader%d", time.Now().UnixNano())
+	password := "пароль-25"
+
+	if len(Token) == 0 {
+		// Users are registered only with TODO_PASSWORD, which needs the admin token
+		return
+	}
+
+	assert.Equal(t, http.StatusBadRequest, requestStatus(t, "api/admin/user", map[string]any{"login": login, "password": password, "role": "owner"}, http.MethodPost),
+		"Неизвестная роль должна вызывать ошибку")
+
+	ret, err := postJSON("api/admin/user", map[string]any{"login": login, "password": password, "role": "editor"}, http.MethodPost)
+	assert.NoError(t, err)
+	userID, _ := ret["id"].(string)
+	if !assert.NotEmpty(t, userID, ret["error"]) {
+		return
+	}
+	defer postJSON("api/admin/user?id="+userID, nil, http.MethodDelete)
+
+	// Editor changes own tasks but does not manage users
+	token := signIn(t, login, password)
+	status, ret := userRequest(t, token, http.MethodPost, "api/task", map[string]any{"title": "Задача редактора"})
+	assert.Equal(t, http.StatusOK, status)
+	taskID, _ := ret["id"].(string)
+	assert.NotEmpty(t, taskID)
+	status, _ = userRequest(t, token, http.MethodPost, "api/admin/backup", nil)
+	assert.Equal(t, http.StatusForbidden, status, "Бекапы доступны только администратору")
+
+	// Role change makes old tokens invalid
+	ret, err = postJSON("api/admin/user", map[string]any{"id": userID, "role": "readonly"}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	status, me := userRequest(t, token, http.MethodGet, "api/user", nil)
+	assert.Equal(t, http.StatusUnauthorized, status, "Токен, выданный до смены роли, не должен действовать")
+
+	// Read-only user reads only
+	token = signIn(t, login, password)
+	status, me = userRequest(t, token, http.MethodGet, "api/user", nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "readonly", me["role"])
+
+	status, ret = userRequest(t, token, http.MethodGet, "api/tasks", nil)
+	assert.Equal(t, http.StatusOK, status)
+	if tasks, ok := ret["tasks"].([]any); assert.True(t, ok) && assert.Len(t, tasks, 1) {
+		assert.Equal(t, taskID, tasks[0].(map[string]any)["id"])
+	}
+	status, _ = userRequest(t, token, http.MethodGet, "api/task?id="+taskID, nil)
+	assert.Equal(t, http.StatusOK, status)
+
+	for _, req := range []struct {
+		method, path string
+		values       map[string]any
+	}{
+		{http.MethodPost, "api/task", map[string]any{"title": "Запрещено"}},
+		{http.MethodPut, "api/task", map[string]any{"id": taskID, "title": "Запрещено", "version": "1"}},
+		{http.MethodPost, "api/task/done?id=" + taskID, nil},
+		{http.MethodDelete, "api/task?id=" + taskID, nil},
+		{http.MethodPost, "api/project", map[string]any{"name": "Запрещено"}},
+		{http.MethodDelete, "api/trash", nil},
+		{http.MethodGet, "api/admin/users", nil},
+	} {
+		status, _ = userRequest(t, token, req.method, req.path, req.values)
+		assert.Equal(t, http.StatusForbidden, status, "%s %s должен быть запрещен для чтения", req.method, req.path)
+	}
+	status, _ = userRequest(t, token, http.MethodGet, "api/task?id="+taskID, nil)
+	assert.Equal(t, http.StatusOK, status, "Задача не должна измениться")
+
+	assert.Equal(t, http.StatusBadRequest, requestStatus(t, "api/admin/user", map[string]any{"id": "1", "role": "editor"}, http.MethodPut),
+		"Нельзя сменить собственную роль")
+}
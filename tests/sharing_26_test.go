@@ -0,0 +1,139 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// addUser registers an editor and returns its ID and token
+func addUser(t *testing.T, login, password string) (string, string) {
+	ret, err := postJSON("api/admin/user", map[string]any{"login": login, "password": password}, http.MethodPost)
+	assert.NoError(t, err)
+	id, _ := ret["id"].(string)
+	assert.NotEmpty(t, id, ret["error"])
+	return id, signIn(t, login, password)
+}
+
+// userTaskIDs returns IDs of tasks listed for the user
+func userTaskIDs(t *testing.T, token, query string) []string {
+	status, ret := userRequest(t, token, http.MethodGet, "api/tasks"+query, nil)
+	assert.Equal(t, http.StatusOK, status)
+	tasks, _ := ret["tasks"].([]any)
+	ids := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.(map[string]any)["id"].(string))
+	}
+	return ids
+}
+
+func TestSharing(t *testing.T) {
+	suffix := time.Now().UnixNano()
+	owner, bob := fmt.Sprintf("owner%d", suffix), fmt.Sprintf("bob%d", suffix)
+	password := "пароль-26"
+
+	if len(Token) == 0 {
+		// Users are registered only with TODO_PASSWORD, which needs the admin token
+		return
+	}
+
+	ownerID, ownerToken := addUser(t, owner, password)
+	bobID, bobToken := addUser(t, bob, password)
+	defer postJSON("api/admin/user?id="+ownerID, nil, http.MethodDelete)
+	defer postJSON("api/admin/user?id="+bobID, nil, http.MethodDelete)
+
+	today := time.Now().Format(`20060102`)
+	status, ret := userRequest(t, ownerToken, http.MethodPost, "api/task",
+		map[string]any{"title": "Передать дела", "date": today, "assignee": bob})
+	assert.Equal(t, http.StatusOK, status, ret["error"])
+	handoff, _ := ret["id"].(string)
+	status, ret = userRequest(t, ownerToken, http.MethodPost, "api/task",
+		map[string]any{"title": "Вынести мусор", "date": today, "repeat": "d 1"})
+	assert.Equal(t, http.StatusOK, status)
+	chore, _ := ret["id"].(string)
+
+	status, ret = userRequest(t, ownerToken, http.MethodPost, "api/task", map[string]any{"title": "Чужой", "assignee": "nobody" + bob})
+	assert.Equal(t, http.StatusBadRequest, status, "Неизвестный исполнитель должен вызывать ошибку")
+
+	// The assignee sees and changes the task, but does not delete or reassign it
+	assert.Equal(t, []string{handoff}, userTaskIDs(t, bobToken, "?scope=assigned"))
+	assert.Equal(t, []string{handoff}, userTaskIDs(t, bobToken, ""))
+	status, task := userRequest(t, bobToken, http.MethodGet, "api/task?id="+handoff, nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, owner, task["owner"])
+	assert.Equal(t, bob, task["assignee"])
+	assert.Equal(t, "edit", task["permission"])
+
+	status, _ = userRequest(t, bobToken, http.MethodPut, "api/task",
+		map[string]any{"id": handoff, "title": "Передать дела и ключи", "date": today, "version": task["version"]})
+	assert.Equal(t, http.StatusOK, status)
+	_, task = userRequest(t, bobToken, http.MethodGet, "api/task?id="+handoff, nil)
+	status, _ = userRequest(t, bobToken, http.MethodPut, "api/task",
+		map[string]any{"id": handoff, "title": "Передать дела", "date": today, "assignee": "", "version": task["version"]})
+	assert.Equal(t, http.StatusForbidden, status, "Исполнитель не может снять назначение")
+	status, _ = userRequest(t, bobToken, http.MethodDelete, "api/task?id="+handoff, nil)
+	assert.Equal(t, http.StatusForbidden, status, "Удалять задачу может только владелец")
+	status, task = userRequest(t, ownerToken, http.MethodGet, "api/task?id="+handoff, nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "Передать дела и ключи", task["title"])
+	assert.Equal(t, "owner", task["permission"])
+
+	// View share
+	assert.NotContains(t, userTaskIDs(t, bobToken, ""), chore)
+	status, ret = userRequest(t, ownerToken, http.MethodPost, "api/task/share?id="+chore, map[string]any{"login": bob, "permission": "view"})
+	assert.Equal(t, http.StatusOK, status, ret["error"])
+	assert.Equal(t, []string{chore}, userTaskIDs(t, bobToken, "?scope=shared"))
+	status, _ = userRequest(t, bobToken, http.MethodPost, "api/task/done?id="+chore, nil)
+	assert.Equal(t, http.StatusForbidden, status, "Просмотр не дает права выполнить задачу")
+	status, _ = userRequest(t, bobToken, http.MethodPost, "api/task/share?id="+chore, map[string]any{"login": bob, "permission": "edit"})
+	assert.Equal(t, http.StatusForbidden, status, "Делиться задачей может только владелец")
+
+	for _, values := range []map[string]any{
+		{"login": "nobody" + bob, "permission": "view"},
+		{"login": bob, "permission": "admin"},
+		{"login": owner, "permission": "edit"},
+	} {
+		status, _ = userRequest(t, ownerToken, http.MethodPost, "api/task/share?id="+chore, values)
+		assert.Equal(t, http.StatusBadRequest, status, values)
+	}
+
+	// Edit share: completing the recurring task moves it for everyone
+	status, _ = userRequest(t, ownerToken, http.MethodPost, "api/task/share?id="+chore, map[string]any{"login": bob, "permission": "edit"})
+	assert.Equal(t, http.StatusOK, status)
+	status, ret = userRequest(t, ownerToken, http.MethodGet, "api/task/shares?id="+chore, nil)
+	assert.Equal(t, http.StatusOK, status)
+	if shares, ok := ret["shares"].([]any); assert.True(t, ok) && assert.Len(t, shares, 1) {
+		assert.Equal(t, bob, shares[0].(map[string]any)["login"])
+		assert.Equal(t, "edit", shares[0].(map[string]any)["permission"])
+	}
+
+	status, _ = userRequest(t, bobToken, http.MethodPost, "api/task/done?id="+chore, nil)
+	assert.Equal(t, http.StatusOK, status)
+	next := time.Now().AddDate(0, 0, 1).Format(`20060102`)
+	for _, token := range []string{ownerToken, bobToken} {
+		status, task = userRequest(t, token, http.MethodGet, "api/task?id="+chore, nil)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, next, task["date"], "Общая повторяющаяся задача должна сдвинуться для всех")
+
+		status, ret = userRequest(t, token, http.MethodGet, "api/completions?id="+chore, nil)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Len(t, ret["completions"], 1, "Выполнение общей задачи видно всем")
+	}
+	status, ret = userRequest(t, ownerToken, http.MethodGet, "api/task/history?id="+chore, nil)
+	assert.Equal(t, http.StatusOK, status)
+	if revisions, ok := ret["revisions"].([]any); assert.True(t, ok) && assert.NotEmpty(t, revisions) {
+		assert.Contains(t, revisions[0].(map[string]any)["actor"], bob)
+	}
+
+	// Leaving the share hides the task
+	status, _ = userRequest(t, bobToken, http.MethodDelete, "api/task/share?id="+chore+"&login="+bob, nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Empty(t, userTaskIDs(t, bobToken, "?scope=shared"))
+	status, _ = userRequest(t, bobToken, http.MethodGet, "api/task?id="+chore, nil)
+	assert.Equal(t, http.StatusNotFound, status)
+
+	assert.Equal(t, http.StatusBadRequest, requestStatus(t, "api/tasks?scope=team", nil, http.MethodGet))
+}
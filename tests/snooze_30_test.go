@@ -0,0 +1,85 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func snooze(t *testing.T, id, to string) map[string]any {
+	ret, err := postJSON("api/task/snooze?id="+id, map[string]any{"to": to}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret["error"])
+	return ret
+}
+
+func TestSnooze(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	now := time.Now()
+	day := func(n int) string { return now.AddDate(0, 0, n).Format(`20060102`) }
+
+	id := addTask(t, task{date: day(0), title: "Позвонить в банк", comment: "snooze-30"})
+	assert.Equal(t, day(1), snooze(t, id, "+1d")["date"])
+	assert.Equal(t, day(8), snooze(t, id, "+1w")["date"], "Смещение отсчитывается от текущего срока")
+	assert.Equal(t, day(3), snooze(t, id, day(3))["date"])
+
+	monday := now.AddDate(0, 0, 4)
+	for monday.Weekday() != time.Monday {
+		monday = monday.AddDate(0, 0, 1)
+	}
+	assert.Equal(t, monday.Format(`20060102`), snooze(t, id, "Next Monday")["date"])
+
+	for _, to := range []string{"", "завтра", "+0d", "+1y", "next someday", day(-1)} {
+		assert.Equal(t, http.StatusBadRequest, requestStatus(t, "api/task/snooze?id="+id, map[string]any{"to": to}, http.MethodPost), to)
+	}
+	assert.Equal(t, http.StatusBadRequest, requestStatus(t, "api/task/snooze", map[string]any{"to": "+1d"}, http.MethodPost))
+	assert.Equal(t, http.StatusNotFound, requestStatus(t, "api/task/snooze?id=999999", map[string]any{"to": "+1d"}, http.MethodPost))
+
+	// Month offset keeps the task at the end of a shorter month
+	for date, want := range map[string]string{"20990131": "20990228", "20960131": "20960229", "20990331": "20990430", "20991231": "21000131"} {
+		month := addTask(t, task{date: date, title: "Закрыть месяц", comment: "snooze-30"})
+		assert.Equal(t, want, snooze(t, month, "+1m")["date"], date)
+		_, err := postJSON("api/task?id="+month, nil, http.MethodDelete)
+		assert.NoError(t, err)
+	}
+
+	history := getHistory(t, id)
+	if assert.NotEmpty(t, history) {
+		assert.Equal(t, "snooze", history[0].Action)
+	}
+
+	// Start date moves together with the due date
+	ret, err := postJSON("api/task", map[string]any{"title": "Подать декларацию", "comment": "snooze-30", "start": day(0), "date": day(2)}, http.MethodPost)
+	assert.NoError(t, err)
+	deferred, _ := ret["id"].(string)
+	ret = snooze(t, deferred, "+2d")
+	assert.Equal(t, day(4), ret["date"])
+	assert.Equal(t, day(2), ret["start"])
+
+	// Recurring task moves the current occurrence only
+	recurring := addTask(t, task{date: day(0), title: "Полить цветы", comment: "snooze-30", repeat: "d 7"})
+	assert.Equal(t, day(2), snooze(t, recurring, "+2d")["date"])
+	var task Task
+	assert.NoError(t, db.Get(&task, `SELECT * FROM scheduler WHERE id=?`, recurring))
+	assert.Equal(t, day(0), task.SnoozedFrom)
+	assert.Equal(t, day(3), snooze(t, recurring, "+1d")["date"])
+
+	_, err = postJSON("api/task/done?id="+recurring, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NoError(t, db.Get(&task, `SELECT * FROM scheduler WHERE id=?`, recurring))
+	assert.Equal(t, day(7), task.Date, "Отложенное повторение не должно сдвигать расписание")
+	assert.Empty(t, task.SnoozedFrom)
+
+	_, err = postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, requestStatus(t, "api/task/snooze?id="+id, map[string]any{"to": "+1d"}, http.MethodPost))
+
+	for _, id := range []string{id, deferred, recurring} {
+		_, err = postJSON("api/task?id="+id, nil, http.MethodDelete)
+		assert.NoError(t, err)
+	}
+}
@@ -0,0 +1,139 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type tagged struct {
+	ID   string   `json:"id"`
+	Tags []string `json:"tags"`
+}
+
+type tagCount struct {
+	Name  string `json:"name"`
+	Tasks int    `json:"tasks"`
+}
+
+func getTagged(t *testing.T, query string) []tagged {
+	body, err := requestJSON("api/tasks?"+query, nil, http.MethodGet)
+	assert.NoError(t, err)
+
+	var m map[string][]tagged
+	assert.NoError(t, json.Unmarshal(body, &m))
+	return m["tasks"]
+}
+
+func getTags(t *testing.T) map[string]int {
+	body, err := requestJSON("api/tags", nil, http.MethodGet)
+	assert.NoError(t, err)
+
+	var m map[string][]tagCount
+	assert.NoError(t, json.Unmarshal(body, &m))
+	tags := make(map[string]int)
+	for _, tag := range m["tags"] {
+		tags[tag.Name] = tag.Tasks
+	}
+	return tags
+}
+
+func TestTags(t *testing.T) {
+	ret, err := postJSON("api/task", map[string]any{
+		"title": "Задача с тегами",
+		"tags":  []string{"#работа", "Срочно"},
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	first, _ := ret["id"].(string)
+
+	ret, err = postJSON("api/task", map[string]any{
+		"title": "Вторая задача с тегом",
+		"tags":  []string{"дом", "срочно"},
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	second, _ := ret["id"].(string)
+	if !assert.NotEmpty(t, first) || !assert.NotEmpty(t, second) {
+		return
+	}
+
+	// Tags are case-insensitive, the first spelling is kept
+	task, err := postJSON("api/task?id="+first, nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.Equal(t, []any{"Срочно", "работа"}, task["tags"])
+
+	tags := getTags(t)
+	assert.Equal(t, 2, tags["Срочно"], "Тег должен быть у двух задач")
+	assert.Equal(t, 1, tags["работа"])
+
+	found := getTagged(t, "search=%23срочно")
+	assert.Len(t, found, 2, "Поиск по #тегу должен вернуть обе задачи")
+	found = getTagged(t, "tag=работа")
+	if assert.Len(t, found, 1) {
+		assert.Equal(t, first, found[0].ID)
+	}
+
+	ret, err = postJSON("api/task", map[string]any{
+		"title": "Плохой тег",
+		"tags":  []string{"a,b"},
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"], "Тег с запятой должен вызывать ошибку")
+
+	// Rename to an existing tag is a conflict, merge is used instead
+	assert.Equal(t, http.StatusConflict, requestStatus(t, "api/tags/rename", map[string]any{
+		"from": "работа", "to": "дом",
+	}, http.MethodPost))
+	assert.Equal(t, http.StatusNotFound, requestStatus(t, "api/tags/rename", map[string]any{
+		"from": "нет-такого", "to": "новый",
+	}, http.MethodPost))
+
+	ret, err = postJSON("api/tags/rename", map[string]any{"from": "работа", "to": "офис"}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Len(t, getTagged(t, "tag=офис"), 1)
+	assert.Empty(t, getTagged(t, "tag=работа"))
+
+	ret, err = postJSON("api/tags/merge", map[string]any{
+		"from": []string{"офис", "дом"}, "to": "дела",
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+
+	tags = getTags(t)
+	assert.Equal(t, 2, tags["дела"], "Объединённый тег должен быть у двух задач")
+	assert.NotContains(t, tags, "офис")
+	assert.NotContains(t, tags, "дом")
+
+	// Tags are kept when update omits them and removed by an empty list
+	_, ret = putTask(t, map[string]any{
+		"id":      first,
+		"title":   "Задача с тегами",
+		"version": taskVersion(t, first),
+	}, "")
+	assert.Empty(t, ret)
+	task, err = postJSON("api/task?id="+first, nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.Equal(t, []any{"Срочно", "дела"}, task["tags"])
+
+	_, ret = putTask(t, map[string]any{
+		"id":      first,
+		"title":   "Задача с тегами",
+		"tags":    []string{},
+		"version": taskVersion(t, first),
+	}, "")
+	assert.Empty(t, ret)
+	task, err = postJSON("api/task?id="+first, nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.Nil(t, task["tags"])
+
+	// Other tests read task lists as strings only, drop tagged tasks
+	for _, id := range []string{first, second} {
+		_, err = postJSON("api/task?id="+id, nil, http.MethodDelete)
+		assert.NoError(t, err)
+		_, err = postJSON("api/trash?id="+id, nil, http.MethodDelete)
+		assert.NoError(t, err)
+	}
+	assert.Empty(t, getTags(t))
+}
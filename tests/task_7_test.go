@@ -32,7 +32,12 @@ func TestDone(t *testing.T) {
 	ret, err := postJSON("api/task/done?id="+id, nil, http.MethodPost)
 	assert.NoError(t, err)
 	assert.Empty(t, ret)
-	notFoundTask(t, id)
+
+	var done Task
+	err = db.Get(&done, `SELECT * FROM scheduler WHERE id=?`, id)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, done.CompletedAt, "Выполненная задача должна остаться с отметкой о выполнении")
+	assert.Empty(t, done.DeletedAt)
 
 	id = addTask(t, task{
 		title:  "Проверить работу /api/task/done",
@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func inTrash(t *testing.T, id string) bool {
+	body, err := requestJSON("api/trash", nil, http.MethodGet)
+	assert.NoError(t, err)
+	var trash tasksPage
+	assert.NoError(t, json.Unmarshal(body, &trash))
+	for _, task := range trash.Tasks {
+		if task["id"] == id {
+			assert.NotEmpty(t, task["deleted_at"])
+			return true
+		}
+	}
+	return false
+}
+
+func TestTrash(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	id := addTask(t, task{
+		title:   "Задача для корзины",
+		comment: "удалить и восстановить",
+	})
+
+	ret, err := postJSON("api/task?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	notFoundTask(t, id)
+	assert.True(t, inTrash(t, id))
+	for _, task := range getTasksPage(t, "").Tasks {
+		assert.NotEqual(t, id, task["id"], "Удалённая задача не должна попадать в список")
+	}
+
+	ret, err = postJSON("api/trash/restore?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.False(t, inTrash(t, id))
+
+	var task Task
+	err = db.Get(&task, `SELECT * FROM scheduler WHERE id=?`, id)
+	assert.NoError(t, err)
+	assert.Empty(t, task.DeletedAt)
+
+	history := getHistory(t, id)
+	if assert.NotEmpty(t, history) {
+		assert.Equal(t, "restore", history[0].Action, "Восстановление должно попадать в историю")
+		assert.Equal(t, "", history[0].Changes["deleted_at"]["new"])
+	}
+
+	ret, err = postJSON("api/trash/restore?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"], "Задачи нет в корзине")
+
+	ret, err = postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.False(t, inTrash(t, id), "Выполненная задача не должна попадать в корзину")
+
+	ret, err = postJSON("api/task?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.True(t, inTrash(t, id))
+
+	ret, err = postJSON("api/trash?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret["error"])
+	assert.False(t, inTrash(t, id))
+
+	var count int
+	err = db.Get(&count, `SELECT count(id) FROM scheduler WHERE id=?`, id)
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+}
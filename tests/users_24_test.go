@@ -0,0 +1,132 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// userRequest sends JSON request signed with the token of a user
+func userRequest(t *testing.T, token, method, apipath string, values map[string]any) (int, map[string]any) {
+	var data []byte
+	if values != nil {
+		var err error
+		data, err = json.Marshal(values)
+		assert.NoError(t, err)
+	}
+
+	req, err := http.NewRequest(method, getURL(apipath), bytes.NewReader(data))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if !assert.NoError(t, err) {
+		return 0, nil
+	}
+	defer resp.Body.Close()
+
+	var m map[string]any
+	json.NewDecoder(resp.Body).Decode(&m)
+	return resp.StatusCode, m
+}
+
+// signIn returns token of the user
+func signIn(t *testing.T, login, password string) string {
+	ret, err := postJSON("api/signin", map[string]any{"login": login, "password": password}, http.MethodPost)
+	assert.NoError(t, err)
+	token, _ := ret["token"].(string)
+	assert.NotEmpty(t, token, ret["error"])
+	return token
+}
+
+func TestUsers(t *testing.T) {
+	login := fmt.Sprintf("user%d", time.Now().UnixNano())
+	password := "пароль-24"
+
+	if len(Token) == 0 {
+		// Without TODO_PASSWORD only the admin account exists
+		assert.Equal(t, http.StatusConflict, requestStatus(t, "api/admin/user", map[string]any{"login": login, "password": password}, http.MethodPost),
+			"Без TODO_PASSWORD регистрация пользователей должна быть запрещена")
+		return
+	}
+
+	ret, err := postJSON("api/admin/user", map[string]any{"login": login, "password": password}, http.MethodPost)
+	assert.NoError(t, err)
+	userID, _ := ret["id"].(string)
+	if !assert.NotEmpty(t, userID, ret["error"]) {
+		return
+	}
+
+	assert.Equal(t, http.StatusConflict, requestStatus(t, "api/admin/user", map[string]any{"login": login, "password": password}, http.MethodPost),
+		"Повторный логин должен вызывать ошибку")
+	assert.Equal(t, http.StatusBadRequest, requestStatus(t, "api/admin/user", map[string]any{"login": login + "x", "password": "short"}, http.MethodPost),
+		"Короткий пароль должен вызывать ошибку")
+	assert.Equal(t, http.StatusUnauthorized, requestStatus(t, "api/signin", map[string]any{"login": login, "password": "wrong password"}, http.MethodPost))
+
+	token := signIn(t, login, password)
+	status, me := userRequest(t, token, http.MethodGet, "api/user", nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, login, me["login"])
+	assert.Equal(t, "editor", me["role"], "По умолчанию пользователь - редактор")
+
+	// Tasks of one user are invisible to the other
+	adminTask := addTask(t, task{title: "Задача администратора"})
+	status, ret = userRequest(t, token, http.MethodPost, "api/task", map[string]any{"title": "Задача пользователя", "tags": []string{"личное"}})
+	assert.Equal(t, http.StatusOK, status)
+	userTask, _ := ret["id"].(string)
+	assert.NotEmpty(t, userTask)
+
+	status, ret = userRequest(t, token, http.MethodGet, "api/tasks", nil)
+	assert.Equal(t, http.StatusOK, status)
+	if tasks, ok := ret["tasks"].([]any); assert.True(t, ok) && assert.Len(t, tasks, 1) {
+		assert.Equal(t, userTask, tasks[0].(map[string]any)["id"])
+	}
+	status, _ = userRequest(t, token, http.MethodGet, "api/task?id="+adminTask, nil)
+	assert.Equal(t, http.StatusNotFound, status, "Чужая задача должна быть недоступна")
+	status, _ = userRequest(t, token, http.MethodDelete, "api/task?id="+adminTask, nil)
+	assert.Equal(t, http.StatusNotFound, status, "Чужую задачу нельзя удалить")
+	notFoundTask(t, userTask)
+	for _, task := range getTasks(t, "") {
+		assert.NotEqual(t, userTask, task["id"])
+	}
+	_, tags := getTags(t)["личное"]
+	assert.False(t, tags, "Теги пользователя не должны быть видны другим")
+
+	status, _ = userRequest(t, token, http.MethodGet, "api/admin/users", nil)
+	assert.Equal(t, http.StatusForbidden, status, "Управление пользователями доступно только администратору")
+
+	body, err := requestJSON("api/admin/users", nil, http.MethodGet)
+	assert.NoError(t, err)
+	var users struct {
+		Users []map[string]any `json:"users"`
+	}
+	assert.NoError(t, json.Unmarshal(body, &users))
+	found := false
+	for _, u := range users.Users {
+		if u["id"] == userID {
+			found = true
+			assert.Nil(t, u["password_hash"])
+		}
+	}
+	assert.True(t, found)
+
+	// Deleting the user removes its tasks
+	ret, err = postJSON("api/admin/user?id="+userID, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+
+	db := openDB(t)
+	defer db.Close()
+	var left int
+	assert.NoError(t, db.Get(&left, `SELECT count(*) FROM scheduler WHERE owner_id = ?`, userID))
+	assert.Zero(t, left)
+
+	_, err = postJSON("api/task?id="+adminTask, nil, http.MethodDelete)
+	assert.NoError(t, err)
+}
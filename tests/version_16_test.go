@@ -0,0 +1,99 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func taskVersion(t *testing.T, id string) string {
+	body, err := requestJSON("api/task?id="+id, nil, http.MethodGet)
+	assert.NoError(t, err)
+	var m map[string]any
+	assert.NoError(t, json.Unmarshal(body, &m))
+	version, _ := m["version"].(string)
+	return version
+}
+
+func putTask(t *testing.T, values map[string]any, ifMatch string) (*http.Response, map[string]any) {
+	data, err := json.Marshal(values)
+	assert.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPut, getURL("api/task"), bytes.NewBuffer(data))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	if ifMatch != "" {
+		req.Header.Set("If-Match", ifMatch)
+	}
+	if len(Token) > 0 {
+		req.AddCookie(&http.Cookie{Name: "token", Value: Token})
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if !assert.NoError(t, err) {
+		return &http.Response{}, nil
+	}
+	defer resp.Body.Close()
+
+	var m map[string]any
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&m))
+	return resp, m
+}
+
+func TestVersion(t *testing.T) {
+	now := time.Now().Format(`20060102`)
+	id := addTask(t, task{
+		date:  now,
+		title: "Правка из двух вкладок",
+	})
+
+	req, err := http.NewRequest(http.MethodGet, getURL("api/task?id="+id), nil)
+	assert.NoError(t, err)
+	if len(Token) > 0 {
+		req.AddCookie(&http.Cookie{Name: "token", Value: Token})
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if !assert.NoError(t, err) {
+		return
+	}
+	resp.Body.Close()
+	tag := resp.Header.Get("ETag")
+	assert.NotEmpty(t, tag, "GET /api/task должен возвращать ETag")
+
+	values := map[string]any{
+		"id":    id,
+		"date":  now,
+		"title": "Правка первой вкладки",
+	}
+
+	resp, ret := putTask(t, values, "")
+	assert.Equal(t, http.StatusPreconditionRequired, resp.StatusCode, "Без версии изменение не допускается")
+	assert.NotEmpty(t, ret["error"])
+
+	resp, ret = putTask(t, values, tag)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, ret)
+	assert.NotEqual(t, tag, resp.Header.Get("ETag"), "После изменения ETag должен измениться")
+
+	values["title"] = "Правка второй вкладки"
+	resp, ret = putTask(t, values, tag)
+	assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode, "Устаревший If-Match должен быть отклонён")
+	current, _ := ret["task"].(map[string]any)
+	assert.Equal(t, "Правка первой вкладки", current["title"], "Ответ должен содержать текущее состояние задачи")
+
+	values["version"] = "1"
+	resp, _ = putTask(t, values, "")
+	assert.Equal(t, http.StatusConflict, resp.StatusCode, "Устаревшая версия должна быть отклонена")
+
+	values["version"] = taskVersion(t, id)
+	resp, _ = putTask(t, values, "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body, err := requestJSON("api/task?id="+id, nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), "Правка второй вкладки")
+}